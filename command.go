@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"community-robot-lib/config"
+	"community-robot-lib/framework"
+	sdk "git-platform-sdk"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// hasPermission checks whether the user is one of the maintainers or committers of the repo.
+func (bot *robot) hasPermission(e *sdk.GenericEvent, user string) (bool, error) {
+	user = strings.ToLower(user)
+
+	sigCli := bot.sigClient(e.Context())
+
+	collaborators, err := bot.cli.WithContext(e.Context()).ListCollaborator(e.Org, e.Repo)
+	if err != nil {
+		return false, err
+	}
+
+	maintainers, err := sigCli.GetRepositoryMaintainerByOrgRepo(e.Org, e.Repo)
+	if err != nil {
+		return false, err
+	}
+
+	committers, _ := sigCli.GetRepositoryCommitterByOrgRepo(e.Org, e.Repo)
+
+	v := sets.NewString()
+	for _, s := range [][]string{collaborators, maintainers, committers} {
+		for _, u := range s {
+			v.Insert(strings.ToLower(u))
+		}
+	}
+
+	return v.Has(user), nil
+}
+
+func (bot *robot) newCommandArgs(e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) (*eventArgs, error) {
+	bc, err := bot.getConfig(cfg, e.Org, e.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &eventArgs{
+		cli:    bot.clientFor(bc).WithContext(e.Context()),
+		sigCli: bot.sigClient(e.Context()),
+		event:  e,
+		cnf:    bc,
+		log:    log,
+	}
+
+	if e.PRNumber != "" {
+		p.flag = PullRequest
+		p.author = e.PRAuthor
+	} else {
+		p.flag = Issue
+		p.author = e.IssueAuthor
+	}
+
+	return p, nil
+}
+
+// handleSigCommand handles "/sig <name>" which replaces the sig label of the issue or PR.
+func (bot *robot) handleSigCommand(cmd *framework.Command, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("invalid sig command, it should be like /sig <name>")
+	}
+
+	p, err := bot.newCommandArgs(e, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	// the label of sig which doesn't exist is not added, such as the one of a typo
+	name, err := p.sigCli.GetSigName(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		log.Warnf("ignore the sig command, since no such sig: %s", cmd.Args[0])
+
+		return nil
+	}
+
+	label := sigLabel(name)
+	if err = bot.createLabelIfNeed(p.cli, e.Org, e.Repo, label); err != nil {
+		return err
+	}
+
+	return bot.syncSigLabel(p, label, true)
+}
+
+// handleAssignCommand handles "/assign [@user ...]" which assigns the issue or PR to the users,
+// or to the commenter if no user is given.
+func (bot *robot) handleAssignCommand(cmd *framework.Command, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error {
+	p, err := bot.newCommandArgs(e, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	users := make([]string, 0, len(cmd.Args))
+	for _, v := range cmd.Args {
+		if u := strings.TrimPrefix(v, "@"); u != "" {
+			users = append(users, u)
+		}
+	}
+
+	if len(users) == 0 {
+		users = append(users, cmd.Commenter)
+	}
+
+	if p.flag == Issue {
+		return p.cli.AssignIssue(&sdk.IssueParameter{
+			Org:       e.Org,
+			Repo:      e.Repo,
+			Number:    e.IssueNumber,
+			Reviewers: users,
+		})
+	}
+
+	return p.cli.AssignPR(&sdk.PRParameter{
+		Org:       e.Org,
+		Repo:      e.Repo,
+		Number:    e.PRNumber,
+		Reviewers: users,
+	})
+}
+
+// handleWelcomeCommand handles "/welcome" which posts the welcome message again.
+func (bot *robot) handleWelcomeCommand(cmd *framework.Command, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error {
+	p, err := bot.newCommandArgs(e, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	if p.sigName, err = p.sigCli.GetSigNameByOrgRepo(e.Org, e.Repo); err != nil {
+		return err
+	}
+
+	return bot.welcome(p)
+}
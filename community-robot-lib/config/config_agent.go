@@ -4,15 +4,30 @@ import (
 	"crypto/md5"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/yaml"
 
+	"community-robot-lib/metrics"
 	"community-robot-lib/utils"
 )
 
+const (
+	// debounceInterval is how long to wait for the following events before reloading,
+	// since saving a file or updating a ConfigMap emits a burst of events.
+	debounceInterval = 500 * time.Millisecond
+
+	// pollInterval is the interval of polling the file if it can't be watched.
+	pollInterval = 1 * time.Minute
+
+	// configMapDataDir is the symlink which Kubernetes swaps atomically when a mounted ConfigMap changes.
+	configMapDataDir = "..data"
+)
+
 type Config interface {
 	Validate() error
 	SetDefault()
@@ -22,49 +37,146 @@ type Config interface {
 // Otherwise, it should deep copy the config when reading it.
 type NewConfig func() Config
 
+// Status is the state of loading the config.
+type Status struct {
+	Path string `json:"path"`
+	// MD5 is the md5 of the config in use, which is the last good one
+	MD5      string    `json:"md5"`
+	LoadedAt time.Time `json:"loaded_at"`
+	// LastError is the error of the last load, it is cleared once the config is loaded successfully
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Subscriber is called with the new config each time it is changed, it should not block.
+type Subscriber func(md5Sum string, c Config)
+
 type ConfigAgent struct {
 	mut    sync.RWMutex
 	c      Config
 	b      NewConfig
 	md5Sum string
+	path   string
 	t      utils.Timer
+
+	loadedAt  time.Time
+	lastErr   error
+	lastErrAt time.Time
+
+	// loadMut serializes the loading by the watcher and Reload
+	loadMut     sync.Mutex
+	subscribers []Subscriber
+
+	watcher *fsnotify.Watcher
+	stop    chan struct{}
+	stopped chan struct{}
+	polling bool
 }
 
 func NewConfigAgent(b NewConfig) ConfigAgent {
 	return ConfigAgent{b: b, t: utils.NewTimer()}
 }
 
+// load loads the config if it is changed, the invalid one is rejected and the old one is kept.
 func (ca *ConfigAgent) load(path string) error {
+	ca.loadMut.Lock()
+	defer ca.loadMut.Unlock()
+
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return ca.recordError(err)
 	}
 
 	content := []byte(os.ExpandEnv(string(b)))
 	md5Sum := fmt.Sprintf("%x", md5.Sum(content))
-	if ca.md5Sum == md5Sum {
+
+	ca.mut.RLock()
+	unchanged := ca.md5Sum == md5Sum
+	ca.mut.RUnlock()
+
+	if unchanged {
+		// it may be reverted to the config in use after a rejected one
+		ca.mut.Lock()
+		ca.lastErr = nil
+		ca.mut.Unlock()
+
+		metrics.ConfigLastReloadSuccessful.Set(1)
+
 		return nil
 	}
 
 	c := ca.b()
 	if err := yaml.Unmarshal(content, c); err != nil {
-		return err
+		return ca.recordError(err)
 	}
 
 	c.SetDefault()
 
 	if err := c.Validate(); err != nil {
-		return err
+		return ca.recordError(err)
 	}
 
 	ca.mut.Lock()
+	old, oldMD5 := ca.c, ca.md5Sum
 	ca.c = c
 	ca.md5Sum = md5Sum
+	ca.loadedAt = time.Now()
+	ca.lastErr = nil
+	subscribers := ca.subscribers
 	ca.mut.Unlock()
 
+	metrics.ConfigReloads.WithLabelValues(metrics.Result(nil)).Inc()
+	metrics.ConfigLastReloadSuccessful.Set(1)
+	metrics.ConfigInfo.Reset()
+	metrics.ConfigInfo.WithLabelValues(md5Sum).Set(1)
+
+	if old != nil {
+		logChanges(old, c, oldMD5, md5Sum, path)
+	}
+
+	for _, f := range subscribers {
+		f(md5Sum, c)
+	}
+
 	return nil
 }
 
+func (ca *ConfigAgent) recordError(err error) error {
+	ca.mut.Lock()
+	ca.lastErr = err
+	ca.lastErrAt = time.Now()
+	ca.mut.Unlock()
+
+	metrics.ConfigReloads.WithLabelValues(metrics.Result(err)).Inc()
+	metrics.ConfigLastReloadSuccessful.Set(0)
+
+	return err
+}
+
+// logChanges logs the paths of fields changed by the reload, the values are not logged
+// since they may be the secrets expanded from the environment variables.
+func logChanges(prev, next Config, oldMD5, newMD5, path string) {
+	l := logrus.WithFields(logrus.Fields{
+		"path":    path,
+		"old_md5": oldMD5,
+		"md5":     newMD5,
+	})
+
+	changes, err := Diff(prev, next)
+	if err != nil {
+		l.WithError(err).Warn("config reloaded, but can't compare it with the old one")
+
+		return
+	}
+
+	diff := make([]string, len(changes))
+	for i := range changes {
+		diff[i] = changes[i].Redacted()
+	}
+
+	l.WithField("diff", diff).Infof("config reloaded, %d fields changed", len(changes))
+}
+
 func (ca *ConfigAgent) GetConfig() (string, Config) {
 	ca.mut.RLock()
 	c := ca.c // copy the pointer
@@ -74,28 +186,172 @@ func (ca *ConfigAgent) GetConfig() (string, Config) {
 	return v, c
 }
 
-// Start starts polling path for plugin config.
+// Status returns the state of loading the config.
+func (ca *ConfigAgent) Status() Status {
+	ca.mut.RLock()
+	defer ca.mut.RUnlock()
+
+	s := Status{
+		Path:     ca.path,
+		MD5:      ca.md5Sum,
+		LoadedAt: ca.loadedAt,
+	}
+
+	if ca.lastErr != nil {
+		s.LastError = ca.lastErr.Error()
+		at := ca.lastErrAt
+		s.LastErrorAt = &at
+	}
+
+	return s
+}
+
+// Subscribe registers f which is called after the config is changed, f is not called
+// with the config loaded before it is registered.
+func (ca *ConfigAgent) Subscribe(f Subscriber) {
+	ca.mut.Lock()
+	// copy on write, so that load can call the subscribers without holding the lock
+	ca.subscribers = append(ca.subscribers[:len(ca.subscribers):len(ca.subscribers)], f)
+	ca.mut.Unlock()
+}
+
+// Start loads the config and watches path to reload it once changed.
+// It falls back to polling path if path can't be watched.
 // If the first attempt fails, then start returns the error.
 func (ca *ConfigAgent) Start(path string) error {
 	if err := ca.load(path); err != nil {
 		return err
 	}
 
+	ca.path = path
+
 	l := logrus.WithField("path", path)
 
+	w, err := ca.watch(path)
+	if err != nil {
+		l.WithError(err).Warn("can't watch the config file, poll it instead")
+
+		ca.poll(l)
+
+		return nil
+	}
+
+	ca.watcher = w
+	ca.stop = make(chan struct{})
+	ca.stopped = make(chan struct{})
+
+	go ca.run(l)
+
+	return nil
+}
+
+// watch watches the directory of path instead of path itself, since the file is replaced
+// rather than written by most editors and by Kubernetes.
+func (ca *ConfigAgent) watch(path string) (*fsnotify.Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		_ = w.Close()
+
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (ca *ConfigAgent) poll(l *logrus.Entry) {
+	ca.polling = true
+
 	ca.t.Start(
 		func() {
-			if err := ca.load(path); err != nil {
+			if err := ca.load(ca.path); err != nil {
 				l.WithError(err).Error("loading config")
 			}
 		},
-		1*time.Minute,
+		pollInterval,
 		0,
 	)
+}
 
-	return nil
+// run reloads the config after the events of it stop coming for debounceInterval.
+// It switches to polling if the watcher stops working.
+func (ca *ConfigAgent) run(l *logrus.Entry) {
+	defer close(ca.stopped)
+	defer ca.watcher.Close()
+
+	debounce := time.NewTimer(debounceInterval)
+	debounce.Stop()
+
+	for {
+		select {
+		case e, ok := <-ca.watcher.Events:
+			if !ok {
+				l.Warn("the config watcher is closed, poll the config file instead")
+				ca.poll(l)
+
+				return
+			}
+
+			if ca.isConfigEvent(e) {
+				debounce.Reset(debounceInterval)
+			}
+
+		case err, ok := <-ca.watcher.Errors:
+			if !ok {
+				l.Warn("the config watcher is closed, poll the config file instead")
+				ca.poll(l)
+
+				return
+			}
+
+			// some events may be lost, such as the queue overflows, so reload anyway
+			l.WithError(err).Error("watching config")
+			debounce.Reset(debounceInterval)
+
+		case <-debounce.C:
+			if err := ca.load(ca.path); err != nil {
+				l.WithError(err).Error("loading config")
+			}
+
+		case <-ca.stop:
+			debounce.Stop()
+
+			return
+		}
+	}
+}
+
+// isConfigEvent reports whether the event may change the content of config file. The file
+// mounted from a ConfigMap is a symlink to ..data/<file>, and only ..data is changed.
+func (ca *ConfigAgent) isConfigEvent(e fsnotify.Event) bool {
+	if e.Op == fsnotify.Chmod {
+		return false
+	}
+
+	name := filepath.Base(e.Name)
+
+	return name == filepath.Base(ca.path) || name == configMapDataDir
+}
+
+// Reload loads the config file immediately instead of waiting for the next change.
+func (ca *ConfigAgent) Reload() error {
+	if ca.path == "" {
+		return fmt.Errorf("config agent is not started")
+	}
+
+	return ca.load(ca.path)
 }
 
 func (ca *ConfigAgent) Stop() {
-	ca.t.Stop()
+	if ca.stop != nil {
+		close(ca.stop)
+		<-ca.stopped
+	}
+
+	if ca.polling {
+		ca.t.Stop()
+	}
 }
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name string `json:"name"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+
+	return nil
+}
+
+func (c *testConfig) SetDefault() {}
+
+func startTestAgent(t *testing.T, path string) (*ConfigAgent, chan string) {
+	t.Helper()
+
+	agent := NewConfigAgent(func() Config { return &testConfig{} })
+	if err := agent.Start(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Cleanup(agent.Stop)
+
+	changed := make(chan string, 10)
+	agent.Subscribe(func(_ string, c Config) {
+		changed <- c.(*testConfig).Name
+	})
+
+	return &agent, changed
+}
+
+func expectChange(t *testing.T, changed chan string, name string) {
+	t.Helper()
+
+	select {
+	case v := <-changed:
+		if v != name {
+			t.Errorf("expected config %s, got %s", name, v)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected config %s, but it is not reloaded", name)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigAgentWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "name: a\n")
+
+	agent, changed := startTestAgent(t, path)
+
+	// the invalid config is rejected, and the old one is kept
+	writeFile(t, path, "name: \n")
+	writeFile(t, path, "name: b\n")
+	expectChange(t, changed, "b")
+
+	// replaced by renaming as most editors do
+	tmp := path + ".tmp"
+	writeFile(t, tmp, "name: c\n")
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectChange(t, changed, "c")
+
+	if _, c := agent.GetConfig(); c.(*testConfig).Name != "c" {
+		t.Errorf("expected config c, got %s", c.(*testConfig).Name)
+	}
+}
+
+// TestConfigAgentConfigMap simulates how Kubernetes updates a mounted ConfigMap, the config file
+// is a symlink to ..data/config.yaml and the symlink ..data is swapped to a new directory.
+func TestConfigAgentConfigMap(t *testing.T) {
+	dir := t.TempDir()
+
+	mkData := func(version, content string) {
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		writeFile(t, filepath.Join(dir, version, "config.yaml"), content)
+
+		tmp := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(version, tmp); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := os.Rename(tmp, filepath.Join(dir, configMapDataDir)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	mkData("..v1", "name: a\n")
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join(configMapDataDir, "config.yaml"), path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, changed := startTestAgent(t, path)
+
+	mkData("..v2", "name: b\n")
+	expectChange(t, changed, "b")
+}
+
+func TestConfigAgentRejectedReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, "name: a\n")
+
+	agent, changed := startTestAgent(t, path)
+	good := agent.Status().MD5
+
+	writeFile(t, path, "name: \n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for agent.Status().LastError == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the invalid config to be rejected")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if s := agent.Status(); s.MD5 != good || s.LastErrorAt == nil {
+		t.Errorf("expected the last good config is kept, got %+v", s)
+	}
+
+	writeFile(t, path, "name: b\n")
+	expectChange(t, changed, "b")
+
+	if s := agent.Status(); s.MD5 == good || s.LastError != "" {
+		t.Errorf("expected the error is cleared, got %+v", s)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change is a field which differs between two configs.
+type Change struct {
+	// Path is the path of field in the config file, such as config_items[2].command_link
+	Path string `json:"path"`
+	// Old is nil if the field is added
+	Old interface{} `json:"old,omitempty"`
+	// New is nil if the field is removed
+	New interface{} `json:"new,omitempty"`
+}
+
+// String includes the values, which may be the secrets expanded from the environment
+// variables, so it should not be logged. Use Redacted instead.
+func (c Change) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("%s: added %s", c.Path, toJSON(c.New))
+	case c.New == nil:
+		return fmt.Sprintf("%s: removed %s", c.Path, toJSON(c.Old))
+	default:
+		return fmt.Sprintf("%s: %s => %s", c.Path, toJSON(c.Old), toJSON(c.New))
+	}
+}
+
+// Redacted describes the change without the values.
+func (c Change) Redacted() string {
+	switch {
+	case c.Old == nil:
+		return c.Path + ": added"
+	case c.New == nil:
+		return c.Path + ": removed"
+	default:
+		return c.Path + ": changed"
+	}
+}
+
+// Diff compares the configs by their json form, and returns the changed fields sorted by path.
+// The lists of scalar values are compared as a whole.
+func Diff(prev, next Config) ([]Change, error) {
+	o, err := toTree(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := toTree(next)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	diffTree("", o, n, &changes)
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+func toTree(c Config) (interface{}, error) {
+	if c == nil || reflect.ValueOf(c).IsNil() {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+
+	return v, err
+}
+
+func diffTree(path string, prev, next interface{}, changes *[]Change) {
+	om, ok1 := prev.(map[string]interface{})
+	nm, ok2 := next.(map[string]interface{})
+	if ok1 && ok2 {
+		for k, v := range om {
+			diffTree(joinPath(path, k), v, nm[k], changes)
+		}
+
+		for k, v := range nm {
+			if _, ok := om[k]; !ok {
+				diffTree(joinPath(path, k), nil, v, changes)
+			}
+		}
+
+		return
+	}
+
+	ol, ok1 := prev.([]interface{})
+	nl, ok2 := next.([]interface{})
+	if ok1 && ok2 && (hasObject(ol) || hasObject(nl)) {
+		for i := 0; i < len(ol) || i < len(nl); i++ {
+			var o, n interface{}
+			if i < len(ol) {
+				o = ol[i]
+			}
+
+			if i < len(nl) {
+				n = nl[i]
+			}
+
+			diffTree(fmt.Sprintf("%s[%d]", path, i), o, n, changes)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(prev, next) {
+		*changes = append(*changes, Change{Path: path, Old: prev, New: next})
+	}
+}
+
+func hasObject(l []interface{}) bool {
+	for _, v := range l {
+		if _, ok := v.(map[string]interface{}); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinPath(path, k string) string {
+	if path == "" {
+		return k
+	}
+
+	return path + "." + k
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testItem struct {
+	Repos []string `json:"repos"`
+	Link  string   `json:"link,omitempty"`
+}
+
+type testItems struct {
+	Items []testItem `json:"items"`
+}
+
+func (c *testItems) Validate() error { return nil }
+
+func (c *testItems) SetDefault() {}
+
+func TestDiff(t *testing.T) {
+	old := &testItems{Items: []testItem{
+		{Repos: []string{"openeuler"}, Link: "a"},
+		{Repos: []string{"openeuler/kernel"}},
+	}}
+
+	next := &testItems{Items: []testItem{
+		{Repos: []string{"openeuler", "src-openeuler"}, Link: "b"},
+		{Repos: []string{"openeuler/kernel"}, Link: "c"},
+		{Repos: []string{"mindspore"}},
+	}}
+
+	changes, err := Diff(old, next)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+
+	expect := []string{
+		`items[0].link: "a" => "b"`,
+		`items[0].repos: ["openeuler"] => ["openeuler","src-openeuler"]`,
+		`items[1].link: added "c"`,
+		`items[2]: added {"repos":["mindspore"]}`,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+
+	if v := changes[0].Redacted(); v != "items[0].link: changed" {
+		t.Errorf("expected the change without values, got %s", v)
+	}
+
+	if changes, _ := Diff(old, old); len(changes) != 0 {
+		t.Errorf("expected no change, got %v", changes)
+	}
+}
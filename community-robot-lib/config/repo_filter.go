@@ -2,41 +2,73 @@ package config
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// The levels of how specifically a filter matches a repo, the higher one takes precedence.
+const (
+	MatchNone = iota
+	MatchOrg
+	MatchPattern
+	MatchExact
+)
+
+// regexPrefix marks the entry which is a regular expression of org/repo.
+const regexPrefix = "re:"
+
+// RepoFilter decides which repos a config applies to. Each entry of Repos and ExcludedRepos is one of:
+//   - org, which matches all the repos of org
+//   - org/repo, which matches the repo exactly
+//   - a glob of org/repo such as openeuler/kernel-*, see path.Match for the syntax
+//   - a regular expression of org/repo prefixed by "re:" such as re:openeuler/kernel(-.*)?,
+//     it is anchored at both ends
+//
+// The more specific entry takes precedence, exact > glob or regular expression > org,
+// and an entry of ExcludedRepos wins over the entry of Repos at the same level.
 type RepoFilter struct {
-	// Repos is either in the form of org/repos or just org.
+	// Repos is in the form of org, org/repo, a glob of org/repo or a regular expression prefixed by "re:".
 	Repos []string `json:"repos" required:"true"`
 
-	// ExcludedRepos is in the form of org/repo.
+	// ExcludedRepos is in the same form as Repos.
 	ExcludedRepos []string `json:"excluded_repos,omitempty"`
 }
 
+// Match returns the level of the most specific entry of Repos which org/repo matches,
+// it is MatchNone if org/repo is excluded.
+func (p RepoFilter) Match(org, repo string) int {
+	fullName := org + "/" + repo
+
+	included := bestMatch(p.Repos, org, fullName)
+	if included == MatchNone {
+		return MatchNone
+	}
+
+	if bestMatch(p.ExcludedRepos, org, fullName) >= included {
+		return MatchNone
+	}
+
+	return included
+}
+
 // The return value will be one of the following cases:
 // true,  false: the config can be applied to the org/repo
 // true,  true:  the config can be applied to the org and org/repo
 // false, true:  the config can be applied to the org except org/repo
 // false, false: the config can be applied to neither org or org/repo
 func (p RepoFilter) CanApply(org, orgRepo string) (applyOrgRepo bool, applyOrg bool) {
-	v := sets.NewString(p.Repos...)
-	if v.Has(orgRepo) {
-		applyOrgRepo = true
-		return
-	}
+	applyOrg = sets.NewString(p.Repos...).Has(org)
 
-	if !v.Has(org) {
-		return
+	if _, repo, ok := strings.Cut(orgRepo, "/"); ok {
+		level := p.Match(org, repo)
+		applyOrgRepo = level != MatchNone
+		applyOrg = applyOrg && (level == MatchOrg || level == MatchNone)
 	}
 
-	applyOrg = true
-
-	if len(p.ExcludedRepos) > 0 && sets.NewString(p.ExcludedRepos...).Has(orgRepo) {
-		return
-	}
-
-	applyOrgRepo = true
 	return
 }
 
@@ -45,24 +77,258 @@ func (p RepoFilter) Validate() error {
 		return fmt.Errorf("some org or org/repo exists in both repos and excluded_repos")
 	}
 
+	for _, v := range p.Repos {
+		if err := validateEntry(v); err != nil {
+			return fmt.Errorf("invalid entry %q of repos, %w", v, err)
+		}
+	}
+
+	for _, v := range p.ExcludedRepos {
+		if err := validateEntry(v); err != nil {
+			return fmt.Errorf("invalid entry %q of excluded_repos, %w", v, err)
+		}
+
+		if !p.canExclude(v) {
+			return fmt.Errorf("the entry %q of excluded_repos never takes effect, since no less specific entry of repos covers it", v)
+		}
+	}
+
 	return nil
 }
 
+// canExclude checks whether the excluded entry can win over any entry of Repos.
+func (p RepoFilter) canExclude(excluded string) bool {
+	switch levelOf(excluded) {
+	case MatchPattern:
+		for _, v := range p.Repos {
+			if levelOf(v) != MatchExact {
+				return true
+			}
+		}
+
+	case MatchExact:
+		org, _, _ := strings.Cut(excluded, "/")
+		for _, v := range p.Repos {
+			if levelOf(v) != MatchExact && matchEntry(v, org, excluded) != MatchNone {
+				return true
+			}
+		}
+	}
+
+	// an org can only exclude the same org of Repos which is not allowed
+	return false
+}
+
 type IRepoFilter interface {
 	CanApply(org, orgRepo string) (applyOrgRepo bool, applyOrg bool)
 }
 
-func Find(org, repo string, cfg []IRepoFilter) int {
-	fullName := fmt.Sprintf("%s/%s", org, repo)
+// RepoMatcher is implemented by the filter which tells how specifically it matches a repo,
+// such as RepoFilter.
+type RepoMatcher interface {
+	Match(org, repo string) int
+}
 
-	index := -1
+// Find returns the index of the filter which matches org/repo most specifically,
+// the first one wins if more than one match at the same level. It is -1 if none matches.
+// The filter which doesn't implement RepoMatcher matches at MatchOrg if it applies to the
+// org, or at MatchExact otherwise.
+func Find(org, repo string, cfg []IRepoFilter) int {
+	index, level := -1, MatchNone
 	for i, item := range cfg {
-		if applyOrgRepo, applyOrg := item.CanApply(org, fullName); applyOrgRepo {
-			if !applyOrg {
-				return i
-			}
-			index = i
+		if l := matchLevel(item, org, repo); l > level {
+			index, level = i, l
 		}
 	}
+
 	return index
 }
+
+func matchLevel(f IRepoFilter, org, repo string) int {
+	if m, ok := f.(RepoMatcher); ok {
+		return m.Match(org, repo)
+	}
+
+	applyOrgRepo, applyOrg := f.CanApply(org, org+"/"+repo)
+	switch {
+	case !applyOrgRepo:
+		return MatchNone
+	case applyOrg:
+		return MatchOrg
+	default:
+		return MatchExact
+	}
+}
+
+// AmbiguousFilters reports the entries of Repos which exist in two filters, and the patterns
+// of different filters which may match the same repo. Both make it ambiguous which one applies
+// to the matched repos, but they are allowed, since Find takes the first of the most specific
+// ones, and the existing configs may have them.
+//
+// Two patterns may match the same repo if the literal prefix of one, which is the part before
+// the first special character, is a prefix of the other's. They are checked only if both literal
+// prefixes include the org, so the overlap of patterns like */kernel or re:open.* is not detected,
+// neither is the one removed by ExcludedRepos.
+func AmbiguousFilters(filters []RepoFilter) []string {
+	var r []string
+
+	owner := map[string]int{}
+
+	type pattern struct {
+		entry  string
+		prefix string
+		item   int
+	}
+
+	patterns := map[string][]pattern{}
+
+	for i := range filters {
+		for _, v := range filters[i].Repos {
+			if j, ok := owner[v]; ok && j != i {
+				r = append(r, fmt.Sprintf("the entry %q of repos exists in both the config item %d and %d", v, j, i))
+
+				continue
+			}
+
+			owner[v] = i
+
+			if levelOf(v) != MatchPattern {
+				continue
+			}
+
+			prefix := literalPrefix(v)
+			org, _, ok := strings.Cut(prefix, "/")
+			if !ok {
+				continue
+			}
+
+			for _, p := range patterns[org] {
+				if p.item != i && (strings.HasPrefix(prefix, p.prefix) || strings.HasPrefix(p.prefix, prefix)) {
+					r = append(r, fmt.Sprintf(
+						"the entry %q of config item %d and %q of config item %d may match the same repos",
+						p.entry, p.item, v, i,
+					))
+				}
+			}
+
+			patterns[org] = append(patterns[org], pattern{entry: v, prefix: prefix, item: i})
+		}
+	}
+
+	return r
+}
+
+// literalPrefix returns the literal string which all the repos matched by the pattern begin with.
+func literalPrefix(entry string) string {
+	if strings.HasPrefix(entry, regexPrefix) {
+		r, err := compileEntry(entry)
+		if err != nil {
+			return ""
+		}
+
+		prefix, _ := r.LiteralPrefix()
+
+		return prefix
+	}
+
+	if i := strings.IndexAny(entry, "*?[\\"); i >= 0 {
+		return entry[:i]
+	}
+
+	return entry
+}
+
+func bestMatch(entries []string, org, fullName string) int {
+	level := MatchNone
+	for _, v := range entries {
+		if l := matchEntry(v, org, fullName); l > level {
+			level = l
+		}
+	}
+
+	return level
+}
+
+// matchEntry returns the level of entry if org or fullName matches it, otherwise MatchNone.
+func matchEntry(entry, org, fullName string) int {
+	switch levelOf(entry) {
+	case MatchOrg:
+		if entry == org {
+			return MatchOrg
+		}
+
+	case MatchExact:
+		if entry == fullName {
+			return MatchExact
+		}
+
+	case MatchPattern:
+		if strings.HasPrefix(entry, regexPrefix) {
+			if r, err := compileEntry(entry); err == nil && r.MatchString(fullName) {
+				return MatchPattern
+			}
+		} else if ok, _ := path.Match(entry, fullName); ok {
+			return MatchPattern
+		}
+	}
+
+	return MatchNone
+}
+
+func levelOf(entry string) int {
+	if strings.HasPrefix(entry, regexPrefix) || strings.ContainsAny(entry, "*?[\\") {
+		return MatchPattern
+	}
+
+	if strings.Contains(entry, "/") {
+		return MatchExact
+	}
+
+	return MatchOrg
+}
+
+func validateEntry(entry string) error {
+	if strings.HasPrefix(entry, regexPrefix) {
+		_, err := compileEntry(entry)
+
+		return err
+	}
+
+	if entry == "" {
+		return fmt.Errorf("it is empty")
+	}
+
+	if strings.Count(entry, "/") > 1 {
+		return fmt.Errorf("it never matches, since it should be org or org/repo")
+	}
+
+	if levelOf(entry) == MatchPattern {
+		if _, err := path.Match(entry, ""); err != nil {
+			return err
+		}
+
+		if !strings.Contains(entry, "/") {
+			return fmt.Errorf("it never matches, since the glob should be of org/repo")
+		}
+	}
+
+	return nil
+}
+
+// regexps caches the compiled regular expressions of entries.
+var regexps sync.Map
+
+func compileEntry(entry string) (*regexp.Regexp, error) {
+	if v, ok := regexps.Load(entry); ok {
+		return v.(*regexp.Regexp), nil
+	}
+
+	r, err := regexp.Compile("^(?:" + strings.TrimPrefix(entry, regexPrefix) + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	regexps.Store(entry, r)
+
+	return r, nil
+}
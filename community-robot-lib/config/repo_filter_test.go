@@ -0,0 +1,93 @@
+package config
+
+import "testing"
+
+func TestFind(t *testing.T) {
+	filters := []RepoFilter{
+		{Repos: []string{"openeuler"}, ExcludedRepos: []string{"openeuler/kernel"}},
+		{Repos: []string{"openeuler/kernel-*", "re:src-openeuler/(gcc|glibc)"}, ExcludedRepos: []string{"openeuler/kernel-test"}},
+		{Repos: []string{"openeuler/kernel-doc"}},
+	}
+
+	v := make([]IRepoFilter, len(filters))
+	for i := range filters {
+		if err := filters[i].Validate(); err != nil {
+			t.Fatalf("Unexpected error of filter %d: %v", i, err)
+		}
+
+		v[i] = filters[i]
+	}
+
+	cases := []struct {
+		org, repo string
+		expect    int
+	}{
+		{"openeuler", "community", 0},
+		{"openeuler", "kernel", -1},
+		{"openeuler", "kernel-tools", 1},
+		// the excluded pattern falls back to the org
+		{"openeuler", "kernel-test", 0},
+		{"openeuler", "kernel-doc", 2},
+		{"src-openeuler", "gcc", 1},
+		{"src-openeuler", "gcc-12", -1},
+	}
+
+	for _, c := range cases {
+		if i := Find(c.org, c.repo, v); i != c.expect {
+			t.Errorf("%s/%s: expected %d, got %d", c.org, c.repo, c.expect, i)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []RepoFilter{
+		{Repos: []string{"openeuler/kernel/doc"}},
+		{Repos: []string{"kernel-*"}},
+		{Repos: []string{"re:openeuler/(kernel"}},
+		{Repos: []string{"openeuler/kernel-*"}, ExcludedRepos: []string{"src-openeuler/gcc"}},
+		{Repos: []string{"openeuler/kernel"}, ExcludedRepos: []string{"openeuler/*"}},
+		{Repos: []string{"openeuler"}, ExcludedRepos: []string{"openeuler"}},
+	}
+
+	for i := range invalid {
+		if err := invalid[i].Validate(); err == nil {
+			t.Errorf("case %d: expected error for %+v", i, invalid[i])
+		}
+	}
+
+	v := AmbiguousFilters([]RepoFilter{{Repos: []string{"openeuler"}}, {Repos: []string{"openeuler"}}})
+	if len(v) != 1 {
+		t.Errorf("Expected a warning for the same entry in two filters, got %v", v)
+	}
+
+	v = AmbiguousFilters([]RepoFilter{{Repos: []string{"openeuler/kernel-*"}}, {Repos: []string{"re:openeuler/kernel(-.*)?"}}})
+	if len(v) != 1 {
+		t.Errorf("Expected a warning for the overlapping patterns in two filters, got %v", v)
+	}
+
+	v = AmbiguousFilters([]RepoFilter{
+		{Repos: []string{"openeuler/kernel-*", "openeuler/kernel-doc"}},
+		{Repos: []string{"openeuler/docs-*", "re:src-openeuler/kernel-.*"}},
+	})
+	if len(v) != 0 {
+		t.Errorf("Unexpected warnings for the disjoint patterns: %v", v)
+	}
+}
+
+type orgFilter string
+
+func (f orgFilter) CanApply(org, orgRepo string) (bool, bool) {
+	return org == string(f), org == string(f)
+}
+
+func TestFindWithoutMatcher(t *testing.T) {
+	v := []IRepoFilter{orgFilter("openeuler"), RepoFilter{Repos: []string{"openeuler/kernel"}}}
+
+	if i := Find("openeuler", "kernel", v); i != 1 {
+		t.Errorf("Expected the exact filter, got %d", i)
+	}
+
+	if i := Find("openeuler", "docs", v); i != 0 {
+		t.Errorf("Expected the org filter, got %d", i)
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a violation of the validation tags of a field.
+type FieldError struct {
+	// Path is the path of field in the config file, such as config_items[2].command_link
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// FieldErrors are all the violations found by ValidateStruct.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	v := make([]string, len(e))
+	for i := range e {
+		v[i] = e[i].Error()
+	}
+
+	return strings.Join(v, "; ")
+}
+
+// ValidateStruct checks the fields of v, which is a struct or a pointer to it, by the tags below.
+//   - required:"true", the field can't be the zero value or empty
+//   - format:"url", the string is an absolute url of http or https
+//   - enum:"a,b,c", the string, or each string of the list, is one of the values
+//   - pattern:"<regular expression>", the string, or each string of the list, matches it
+//   - min:"n" and max:"n", the bounds of number, or the length of string, list and map
+//
+// The tags except required, and min and max of number, are not checked if the field is empty,
+// so that zero is still bounded since it is a valid number rather than unset. The nested structs are
+// checked too, and the fields are named by their json tags. path is the path of v in the
+// config file, it prefixes the path of each violation. It returns FieldErrors if any.
+func ValidateStruct(v interface{}, path string) error {
+	var errs FieldErrors
+	validateValue(reflect.ValueOf(v), path, &errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func validateValue(v reflect.Value, path string, errs *FieldErrors) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			validateValue(v.Elem(), path, errs)
+		}
+
+	case reflect.Struct:
+		validateFields(v, path, errs)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case reflect.Map:
+		// sort the keys, so that the violations are reported in a stable order
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, k := range keys {
+			validateValue(v.MapIndex(k), joinPath(path, fmt.Sprint(k.Interface())), errs)
+		}
+	}
+}
+
+func validateFields(v reflect.Value, path string, errs *FieldErrors) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		// the fields of embedded struct are inlined by json
+		if f.Anonymous && name == "" {
+			validateValue(fv, path, errs)
+
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fieldPath := joinPath(path, name)
+
+		for _, msg := range checkTags(f.Tag, fv) {
+			*errs = append(*errs, FieldError{Path: fieldPath, Message: msg})
+		}
+
+		validateValue(fv, fieldPath, errs)
+	}
+}
+
+// checkTags returns the violations of the tags of field whose value is v.
+func checkTags(tag reflect.StructTag, v reflect.Value) []string {
+	if v.IsZero() || isEmpty(v) {
+		if tag.Get("required") == "true" {
+			return []string{"is required"}
+		}
+
+		if !isNumber(v) {
+			return nil
+		}
+
+		return checkBounds(tag, v)
+	}
+
+	var msgs []string
+
+	if format := tag.Get("format"); format != "" {
+		if msg := checkFormat(format, v); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if enum := tag.Get("enum"); enum != "" {
+		values := strings.Split(enum, ",")
+
+		eachString(v, func(s string) {
+			for _, item := range values {
+				if s == item {
+					return
+				}
+			}
+
+			msgs = append(msgs, fmt.Sprintf("%q is not one of %s", s, strings.Join(values, ", ")))
+		})
+	}
+
+	if pattern := tag.Get("pattern"); pattern != "" {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return append(msgs, fmt.Sprintf("invalid pattern tag %q, %v", pattern, err))
+		}
+
+		eachString(v, func(s string) {
+			if !r.MatchString(s) {
+				msgs = append(msgs, fmt.Sprintf("%q does not match %s", s, pattern))
+			}
+		})
+	}
+
+	return append(msgs, checkBounds(tag, v)...)
+}
+
+func checkBounds(tag reflect.StructTag, v reflect.Value) []string {
+	var msgs []string
+
+	for _, bound := range []string{"min", "max"} {
+		if limit := tag.Get(bound); limit != "" {
+			if msg := checkBound(bound, limit, v); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+
+	return msgs
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+
+	return false
+}
+
+func checkFormat(format string, v reflect.Value) string {
+	if format != "url" {
+		return fmt.Sprintf("unknown format tag %q", format)
+	}
+
+	if v.Kind() != reflect.String {
+		return "the url format only applies to string"
+	}
+
+	u, err := url.ParseRequestURI(v.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Sprintf("%q is not a valid url", v.String())
+	}
+
+	return ""
+}
+
+// eachString calls f with the string or each string of the list.
+func eachString(v reflect.Value, f func(string)) {
+	switch {
+	case v.Kind() == reflect.String:
+		f(v.String())
+
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			f(v.Index(i).String())
+		}
+	}
+}
+
+func checkBound(bound, limit string, v reflect.Value) string {
+	n, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid %s tag %q", bound, limit)
+	}
+
+	var actual float64
+	what := "it"
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		actual, what = float64(v.Len()), "the length"
+	default:
+		return fmt.Sprintf("the %s tag does not apply to %s", bound, v.Kind())
+	}
+
+	if bound == "min" && actual < n {
+		return fmt.Sprintf("%s should be at least %s", what, limit)
+	}
+
+	if bound == "max" && actual > n {
+		return fmt.Sprintf("%s should be at most %s", what, limit)
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testLabel struct {
+	Name  string `json:"name" required:"true" pattern:"^[a-z/-]+$"`
+	Color string `json:"color,omitempty" min:"6" max:"7"`
+}
+
+type testBot struct {
+	RepoFilter
+
+	Link     string            `json:"link" required:"true" format:"url"`
+	Actions  []string          `json:"actions,omitempty" enum:"opened,closed"`
+	Retries  int               `json:"retries,omitempty" max:"5"`
+	Workers  int               `json:"workers,omitempty" min:"1"`
+	Labels   []testLabel       `json:"labels,omitempty"`
+	ByName   map[string]string `json:"by_name,omitempty" max:"1"`
+	Ignored  string            `json:"-" required:"true"`
+	internal string            `required:"true"`
+}
+
+type testBots struct {
+	Items []testBot `json:"config_items"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	cfg := &testBots{Items: []testBot{
+		{
+			RepoFilter: RepoFilter{Repos: []string{"openeuler"}},
+			Link:       "https://gitee.com/openeuler/community",
+			Actions:    []string{"opened"},
+			Workers:    1,
+			Labels:     []testLabel{{Name: "sig/kernel", Color: "#0e8a16"}},
+		},
+		{
+			Link:    "gitee.com/openeuler",
+			Actions: []string{"opened", "merged"},
+			Retries: 10,
+			Labels:  []testLabel{{Color: "red"}, {Name: "Kind/Bug"}},
+			ByName:  map[string]string{"a": "", "b": ""},
+		},
+	}}
+
+	err := ValidateStruct(cfg, "")
+
+	var errs FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+
+	expect := []string{
+		"config_items[1].repos: is required",
+		`config_items[1].link: "gitee.com/openeuler" is not a valid url`,
+		`config_items[1].actions: "merged" is not one of opened, closed`,
+		"config_items[1].retries: it should be at most 5",
+		"config_items[1].workers: it should be at least 1",
+		"config_items[1].labels[0].name: is required",
+		"config_items[1].labels[0].color: the length should be at least 6",
+		`config_items[1].labels[1].name: "Kind/Bug" does not match ^[a-z/-]+$`,
+		"config_items[1].by_name: the length should be at most 1",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected\n%v\ngot\n%v", expect, got)
+	}
+
+	if err := ValidateStruct(cfg.Items[0], "config_items[0]"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package framework
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	sdk "git-platform-sdk"
+
+	"github.com/sirupsen/logrus"
+)
+
+const adminPathPrefix = "/admin/"
+
+// admin serves the api to inspect and operate the running robot.
+// Every request must carry the admin token as a bearer token.
+type admin struct {
+	d     *dispatcher
+	token func() []byte
+	mux   *http.ServeMux
+}
+
+func newAdmin(d *dispatcher, token func() []byte) *admin {
+	a := &admin{d: d, token: token, mux: http.NewServeMux()}
+
+	a.mux.HandleFunc(adminPathPrefix+"config", a.getConfig)
+	a.mux.HandleFunc(adminPathPrefix+"config/reload", a.reloadConfig)
+	a.mux.HandleFunc(adminPathPrefix+"events", a.listEvents)
+	a.mux.HandleFunc(adminPathPrefix+"replay", a.replay)
+	a.mux.HandleFunc(adminPathPrefix+"trigger", a.trigger)
+
+	return a
+}
+
+func (a *admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, adminError("unauthorized"))
+
+		return
+	}
+
+	a.mux.ServeHTTP(w, r)
+}
+
+func (a *admin) authorized(r *http.Request) bool {
+	token := a.token()
+	if len(token) == 0 {
+		return false
+	}
+
+	v := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(v), token) == 1
+}
+
+// getConfig handles GET /admin/config
+func (a *admin) getConfig(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	md5Sum, c := a.d.agent.GetConfig()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"md5":    md5Sum,
+		"config": c,
+		"status": a.d.agent.Status(),
+	})
+}
+
+// reloadConfig handles POST /admin/config/reload
+func (a *admin) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	if err := a.d.agent.Reload(); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, adminError(err.Error()))
+
+		return
+	}
+
+	md5Sum, _ := a.d.agent.GetConfig()
+
+	writeJSON(w, http.StatusOK, map[string]string{"md5": md5Sum})
+}
+
+// listEvents handles GET /admin/events
+func (a *admin) listEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a.d.inflight.list())
+}
+
+// replay handles POST /admin/replay?uuid=<event uuid> which replays a recently received event,
+// or POST /admin/replay?event_type=<type>&event_name=<name> whose body is the raw payload of event.
+func (a *admin) replay(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	q := r.URL.Query()
+
+	var e sdk.GenericEvent
+
+	if uuid := q.Get("uuid"); uuid != "" {
+		if a.d.inflight.has(uuid) {
+			writeJSON(w, http.StatusConflict, adminError("the event is being handled: "+uuid))
+
+			return
+		}
+
+		v, err := a.d.recent.get(uuid)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, adminError(err.Error()))
+
+			return
+		}
+
+		if v == nil {
+			writeJSON(w, http.StatusNotFound, adminError("no such event: "+uuid))
+
+			return
+		}
+
+		e = *v
+	} else {
+		t, err := strconv.Atoi(q.Get("event_type"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, adminError("invalid event_type"))
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil || len(body) == 0 {
+			writeJSON(w, http.StatusBadRequest, adminError("missing payload"))
+
+			return
+		}
+
+		e = sdk.GenericEvent{
+			EventType: t,
+			EventName: q.Get("event_name"),
+			Payload:   body,
+		}
+	}
+
+	a.run(w, &e)
+}
+
+// trigger handles POST /admin/trigger?kind=issue|pr&org=<org>&repo=<repo>&number=<number>&author=<author>
+// which runs the handler of the issue or PR event as if the issue or PR has been just created.
+func (a *admin) trigger(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	q := r.URL.Query()
+
+	e := sdk.GenericEvent{
+		EventName: "admin-trigger",
+		Action:    sdk.ActionStateCreated,
+		Org:       q.Get("org"),
+		Repo:      q.Get("repo"),
+	}
+	number, author := q.Get("number"), q.Get("author")
+
+	if e.Org == "" || e.Repo == "" || number == "" {
+		writeJSON(w, http.StatusBadRequest, adminError("org, repo and number are required"))
+
+		return
+	}
+
+	switch kind := q.Get("kind"); kind {
+	case "issue":
+		e.EventType = IssueEvent
+		e.IssueNumber = number
+		e.IssueAuthor = author
+
+	case "pr":
+		e.EventType = PullRequestEvent
+		e.PRNumber = number
+		e.PRAuthor = author
+
+	default:
+		writeJSON(w, http.StatusBadRequest, adminError(fmt.Sprintf("unknown kind: %s", kind)))
+
+		return
+	}
+
+	a.run(w, &e)
+}
+
+func (a *admin) run(w http.ResponseWriter, e *sdk.GenericEvent) {
+	if e.EventType < AccessEvent || e.EventType > PullRequestCommentEvent {
+		writeJSON(w, http.StatusBadRequest, adminError("unknown event type"))
+
+		return
+	}
+
+	l := logrus.WithFields(e.ConvertToMap()).WithField("admin", true)
+
+	span := startSpan(a.d.ctx, e, "admin")
+	defer span.End()
+
+	a.d.wg.Add(1)
+	defer a.d.wg.Done()
+
+	if err := a.d.process(e, l); err != nil {
+		span.RecordError(err)
+		l.WithError(err).Error()
+		writeJSON(w, http.StatusInternalServerError, adminError(err.Error()))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"result": "done"})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	writeJSON(w, http.StatusMethodNotAllowed, adminError("method not allowed"))
+
+	return false
+}
+
+func adminError(msg string) map[string]string {
+	return map[string]string{"error": msg}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Error("write admin response")
+	}
+}
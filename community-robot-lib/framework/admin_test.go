@@ -0,0 +1,49 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "git-platform-sdk"
+)
+
+func TestRecentEventsCopy(t *testing.T) {
+	var re recentEvents
+
+	e := &sdk.GenericEvent{EventUUID: "u1", Org: "org", Payload: []byte(`{"action":"open"}`)}
+	re.add(e)
+
+	// the kept one is not changed by the handler of the received one
+	e.Org = "changed"
+	e.Payload[2] = 'X'
+
+	v, err := re.get("u1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v.Org != "org" || string(v.Payload) != `{"action":"open"}` {
+		t.Errorf("Expected a copy of the received event, got %+v", v)
+	}
+
+	v.Payload[2] = 'Y'
+	if w, _ := re.get("u1"); string(w.Payload) != `{"action":"open"}` {
+		t.Errorf("Expected each replay gets a new copy, got %s", w.Payload)
+	}
+}
+
+func TestReplayInflightEvent(t *testing.T) {
+	a := &admin{d: &dispatcher{}}
+
+	e := &sdk.GenericEvent{EventUUID: "u1", EventType: IssueEvent}
+	a.d.recent.add(e)
+	a.d.inflight.add(e)
+
+	w := httptest.NewRecorder()
+	a.replay(w, httptest.NewRequest(http.MethodPost, "/admin/replay?uuid=u1", nil))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected the replay of event being handled is rejected, got %d", w.Code)
+	}
+}
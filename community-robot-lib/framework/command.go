@@ -0,0 +1,143 @@
+package framework
+
+import (
+	"regexp"
+	"strings"
+
+	"community-robot-lib/config"
+	"community-robot-lib/utils"
+	sdk "git-platform-sdk"
+
+	"github.com/sirupsen/logrus"
+)
+
+// a command must be at the beginning of a line, such as "/sig kernel"
+var commandRe = regexp.MustCompile(`(?m)^/([a-zA-Z][-a-zA-Z0-9_]*)[ \t]*(.*?)[ \t\r]*$`)
+
+// Command is a command parsed from a comment.
+type Command struct {
+	// Name is the lower case name of command without the leading '/'.
+	Name string
+
+	// Args are the arguments split by whitespace.
+	Args []string
+
+	// Commenter is the user who wrote the command.
+	Commenter string
+}
+
+// ParseCommands parses all the commands in the comment.
+func ParseCommands(comment string) []Command {
+	var r []Command
+
+	for _, m := range commandRe.FindAllStringSubmatch(comment, -1) {
+		cmd := Command{Name: strings.ToLower(m[1])}
+		if m[2] != "" {
+			cmd.Args = strings.Fields(m[2])
+		}
+
+		r = append(r, cmd)
+	}
+
+	return r
+}
+
+// CommandHandler handles a command of the comment event.
+type CommandHandler func(cmd *Command, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error
+
+// PermissionChecker checks whether the user can run the privileged commands on the repo of event.
+type PermissionChecker func(e *sdk.GenericEvent, user string) (bool, error)
+
+type commandEntry struct {
+	handler    CommandHandler
+	privileged bool
+}
+
+// Commands dispatches the commands of the comment events to the registered handlers.
+type Commands struct {
+	entries map[string]commandEntry
+	checker PermissionChecker
+}
+
+// NewCommands creates a Commands which uses checker to check the permission of privileged commands.
+func NewCommands(checker PermissionChecker) *Commands {
+	return &Commands{
+		entries: map[string]commandEntry{},
+		checker: checker,
+	}
+}
+
+// Register registers a handler of command. Only the user who passes the permission
+// check can run the command if it is privileged.
+func (c *Commands) Register(name string, fn CommandHandler, privileged bool) {
+	c.entries[strings.ToLower(name)] = commandEntry{handler: fn, privileged: privileged}
+}
+
+// Handle is a GenericHandler which can be registered as the handler of comment events.
+func (c *Commands) Handle(e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error {
+	// the event can't be handled by retrying, so it is not a failure of handler
+	if err := e.ParsePayload(); err != nil {
+		log.WithError(err).Warn("parse the payload")
+
+		return nil
+	}
+
+	if e.Action != "" && e.Action != sdk.ActionStateCreated {
+		return nil
+	}
+
+	comment, commenter := e.IssueComment, e.IssueCommenter
+	if e.PRNumber != "" {
+		comment, commenter = e.PRComment, e.PRCommenter
+	}
+
+	cmds := ParseCommands(comment)
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	var allowed *bool
+	canRun := func(entry commandEntry) (bool, error) {
+		if !entry.privileged {
+			return true, nil
+		}
+
+		if allowed == nil {
+			b, err := c.checker(e, commenter)
+			if err != nil {
+				return false, err
+			}
+			allowed = &b
+		}
+
+		return *allowed, nil
+	}
+
+	mErr := utils.NewMultiErrors()
+	for i := range cmds {
+		cmd := &cmds[i]
+		cmd.Commenter = commenter
+
+		entry, ok := c.entries[cmd.Name]
+		if !ok {
+			continue
+		}
+
+		l := log.WithField("command", cmd.Name)
+
+		b, err := canRun(entry)
+		if err != nil {
+			mErr.AddError(err)
+			continue
+		}
+
+		if !b {
+			l.Infof("%s has no permission to run the command", commenter)
+			continue
+		}
+
+		mErr.AddError(entry.handler(cmd, e, cfg, l))
+	}
+
+	return mErr.Err()
+}
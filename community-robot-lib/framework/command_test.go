@@ -0,0 +1,57 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "git-platform-sdk"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseCommands(t *testing.T) {
+	testCases := []struct {
+		description string
+		comment     string
+		expected    []Command
+	}{
+		{
+			description: "no command",
+			comment:     "looks good to me",
+		},
+		{
+			description: "command with args",
+			comment:     "/sig  kernel \r\n",
+			expected:    []Command{{Name: "sig", Args: []string{"kernel"}}},
+		},
+		{
+			description: "multiple commands with upper case name",
+			comment:     "thanks\n/Assign @a @b\n/welcome",
+			expected: []Command{
+				{Name: "assign", Args: []string{"@a", "@b"}},
+				{Name: "welcome"},
+			},
+		},
+		{
+			description: "command not at the beginning of line",
+			comment:     "please run /welcome",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := ParseCommands(tc.comment); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleInvalidPayload(t *testing.T) {
+	c := NewCommands(func(*sdk.GenericEvent, string) (bool, error) { return true, nil })
+
+	e := &sdk.GenericEvent{EventType: IssueCommentEvent, Payload: []byte(`{"comment":`)}
+	if err := c.Handle(e, nil, logrus.NewEntry(logrus.New())); err != nil {
+		t.Errorf("Expected the invalid payload is not a failure, got %v", err)
+	}
+}
@@ -1,14 +1,21 @@
 package framework
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"sync"
+	"time"
 
 	"community-robot-lib/config"
+	"community-robot-lib/metrics"
+	"community-robot-lib/tracing"
 	sdk "git-platform-sdk"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -26,6 +33,36 @@ type dispatcher struct {
 
 	// secret usage
 	hmac func() []byte
+
+	// ctx is the parent of the context of all events, it is cancelled when shutting down
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// timeout is the deadline of handling an event
+	timeout time.Duration
+
+	// handlerList is the handlers indexed by event type
+	handlerList []ContextHandler
+
+	// Tracks the events being handled and the recently received ones for admin api
+	inflight inflightEvents
+	recent   recentEvents
+}
+
+func newDispatcher(agent *config.ConfigAgent, h handlers, hmac func() []byte, timeout time.Duration) *dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &dispatcher{
+		agent:   agent,
+		h:       h,
+		hmac:    hmac,
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: timeout,
+	}
+	d.initialClient()
+
+	return d
 }
 
 func (d *dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -37,13 +74,22 @@ func (d *dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	l := logrus.WithFields(ge.ConvertToMap())
 
+	// the handler runs after the request finishes, so it can't use the context of request
+	startSpan(tracing.ExtractHTTP(d.ctx, r.Header), ge, "webhook")
+
+	d.recent.add(ge)
+
 	if err := d.Dispatch(ge, l); err != nil {
 		l.WithError(err).Error()
 	}
 }
 
 func (d *dispatcher) Dispatch(event *sdk.GenericEvent, l *logrus.Entry) error {
+	metrics.EventsReceived.WithLabelValues(eventTypeName(event.EventType), event.Org).Inc()
+
 	if event.EventType < AccessEvent || event.EventType > PullRequestCommentEvent {
+		metrics.EventsIgnored.WithLabelValues(eventTypeName(event.EventType), event.Org).Inc()
+		trace.SpanFromContext(event.Context()).End()
 		l.Debug("Ignoring unknown event type")
 	} else {
 
@@ -58,8 +104,25 @@ func (d *dispatcher) Wait() {
 	d.wg.Wait() // Handle remaining requests
 }
 
-var handlerList []GenericHandler
-var once sync.Once
+// Shutdown waits for the running handlers for the grace period at most,
+// and then cancels the context of them and waits them to exit.
+func (d *dispatcher) Shutdown(grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		d.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(grace):
+		logrus.Warn("cancel the handlers which are still running after the grace period")
+		d.cancel()
+		<-done
+	}
+
+	d.cancel()
+}
 
 // Event-Type Value
 const (
@@ -72,8 +135,28 @@ const (
 	PullRequestCommentEvent
 )
 
+var eventTypeNames = []string{
+	"access",
+	"issue",
+	"pull_request",
+	"push",
+	"issue_comment",
+	"pull_request_review",
+	"pull_request_comment",
+}
+
+// eventTypeName returns the name of event type used as the label of metrics.
+func eventTypeName(t int) string {
+	if t < AccessEvent || t > PullRequestCommentEvent {
+		return "unknown"
+	}
+
+	return eventTypeNames[t]
+}
+
+// initialClient indexes the handlers by event type.
 func (d *dispatcher) initialClient() {
-	handlerList = []GenericHandler{
+	d.handlerList = []ContextHandler{
 		d.h.accessHandlers,
 		d.h.issueHandlers,
 		d.h.pullRequestHandler,
@@ -84,11 +167,6 @@ func (d *dispatcher) initialClient() {
 	}
 }
 
-func GetClientInstance(d *dispatcher) *[]GenericHandler {
-	once.Do(d.initialClient)
-	return &handlerList
-}
-
 func (d *dispatcher) getConfig() config.Config {
 	_, c := d.agent.GetConfig()
 
@@ -99,14 +177,203 @@ func (d *dispatcher) getConfig() config.Config {
 func (d *dispatcher) handleEvent(e *sdk.GenericEvent, l *logrus.Entry) {
 	defer d.wg.Done()
 
-	fn := (*GetClientInstance(d))[e.EventType]
-	if err := fn(e, d.getConfig(), l); err != nil {
+	span := trace.SpanFromContext(e.Context())
+	defer span.End()
+
+	// the calls rate limited are retried by the sdk, the handler is not run again,
+	// since the writes done before the failed call would be repeated
+	if err := d.process(e, l); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		if wait, ok := sdk.IsRateLimited(err); ok {
+			l = l.WithField("retry_after", wait.String())
+		}
+
 		l.WithError(err).Error()
 	} else {
 		l.Info()
 	}
 }
 
+// startSpan starts the span of handling the event and sets it to the context of event.
+func startSpan(parent context.Context, e *sdk.GenericEvent, name string) trace.Span {
+	ctx, span := tracing.Tracer().Start(parent, name, trace.WithAttributes(
+		attribute.String("event.uuid", e.EventUUID),
+		attribute.String("event.name", e.EventName),
+		attribute.String("event.type", eventTypeName(e.EventType)),
+		attribute.String("org", e.Org),
+		attribute.String("repo", e.Repo),
+	))
+
+	e.SetContext(ctx)
+
+	return span
+}
+
+// process runs the handler registered for the event type synchronously.
+func (d *dispatcher) process(e *sdk.GenericEvent, l *logrus.Entry) error {
+	name := eventTypeName(e.EventType)
+
+	fn := d.handlerList[e.EventType]
+	if fn == nil {
+		metrics.EventsIgnored.WithLabelValues(name, e.Org).Inc()
+		l.Debug("No handler for the event type")
+
+		return nil
+	}
+
+	d.inflight.add(e)
+	metrics.InflightHandlers.Inc()
+
+	defer func(start time.Time) {
+		d.inflight.remove(e)
+		metrics.InflightHandlers.Dec()
+		metrics.HandlerDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	}(time.Now())
+
+	ctx := e.Context()
+	defer e.SetContext(ctx)
+
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+	e.SetContext(ctx)
+
+	err := fn(ctx, e, d.getConfig(), l)
+	if err != nil {
+		metrics.EventsFailed.WithLabelValues(name, e.Org).Inc()
+	}
+
+	return err
+}
+
+type inflightEvent struct {
+	EventUUID string    `json:"event_uuid"`
+	EventName string    `json:"event_name"`
+	EventType int       `json:"event_type"`
+	Org       string    `json:"org"`
+	Repo      string    `json:"repo"`
+	Started   time.Time `json:"started"`
+}
+
+// inflightEvents tracks the events which are being handled.
+type inflightEvents struct {
+	mut    sync.Mutex
+	events map[*sdk.GenericEvent]time.Time
+}
+
+func (ie *inflightEvents) add(e *sdk.GenericEvent) {
+	ie.mut.Lock()
+	if ie.events == nil {
+		ie.events = map[*sdk.GenericEvent]time.Time{}
+	}
+	ie.events[e] = time.Now()
+	ie.mut.Unlock()
+}
+
+func (ie *inflightEvents) remove(e *sdk.GenericEvent) {
+	ie.mut.Lock()
+	delete(ie.events, e)
+	ie.mut.Unlock()
+}
+
+// has reports whether the event of uuid is being handled.
+func (ie *inflightEvents) has(uuid string) bool {
+	ie.mut.Lock()
+	defer ie.mut.Unlock()
+
+	for e := range ie.events {
+		if e.EventUUID == uuid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ie *inflightEvents) list() []inflightEvent {
+	ie.mut.Lock()
+	defer ie.mut.Unlock()
+
+	r := make([]inflightEvent, 0, len(ie.events))
+	for e, t := range ie.events {
+		r = append(r, inflightEvent{
+			EventUUID: e.EventUUID,
+			EventName: e.EventName,
+			EventType: e.EventType,
+			Org:       e.Org,
+			Repo:      e.Repo,
+			Started:   t,
+		})
+	}
+
+	return r
+}
+
+// the number of recently received events which can be replayed by uuid
+const recentEventsLimit = 200
+
+// recentEvents keeps the encoded copies of recently received events, so that the replayed
+// one shares nothing with the one being handled. The oldest one is dropped when it is full.
+type recentEvents struct {
+	mut    sync.Mutex
+	order  []string
+	events map[string][]byte
+}
+
+func (re *recentEvents) add(e *sdk.GenericEvent) {
+	if e.EventUUID == "" {
+		return
+	}
+
+	b, err := e.ConvertToBytes()
+	if err != nil {
+		logrus.WithError(err).Warn("can't keep the event for replaying")
+
+		return
+	}
+
+	re.mut.Lock()
+	defer re.mut.Unlock()
+
+	if re.events == nil {
+		re.events = map[string][]byte{}
+	}
+
+	if _, ok := re.events[e.EventUUID]; ok {
+		return
+	}
+
+	if len(re.order) >= recentEventsLimit {
+		delete(re.events, re.order[0])
+		re.order = re.order[1:]
+	}
+
+	re.order = append(re.order, e.EventUUID)
+	re.events[e.EventUUID] = b
+}
+
+// get returns a new copy of the event of uuid, it is nil if not found.
+func (re *recentEvents) get(uuid string) (*sdk.GenericEvent, error) {
+	re.mut.Lock()
+	b, ok := re.events[uuid]
+	re.mut.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	e := new(sdk.GenericEvent)
+	if err := e.ConvertFromBytes(b); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte) *sdk.GenericEvent {
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -139,7 +406,12 @@ func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte)
 			return nil
 		}
 
-		if err = sdk.AuthSign(&r.Header, &body, getHmac()); err != nil {
+		var key []byte
+		if getHmac != nil {
+			key = getHmac()
+		}
+
+		if err = sdk.AuthSign(&r.Header, &body, key); err != nil {
 			resp(http.StatusForbidden, "403 Forbidden: "+err.Error())
 			return nil
 		}
@@ -154,5 +426,13 @@ func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte)
 	}
 
 	ge.Payload = body
+
+	// the comments of issue and PR are sent as the same event
+	if ge.EventType == IssueCommentEvent {
+		if e, err := ge.AsCommentEvent(); err == nil && e.IsPR() {
+			ge.EventType = PullRequestCommentEvent
+		}
+	}
+
 	return &ge
 }
@@ -1,6 +1,8 @@
 package framework
 
 import (
+	"context"
+
 	"community-robot-lib/config"
 	sdk "git-platform-sdk"
 	"github.com/sirupsen/logrus"
@@ -8,47 +10,83 @@ import (
 
 type GenericHandler func(e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error
 
+// ContextHandler is the handler which accepts the context of event. The context is
+// cancelled when the deadline of event exceeds or the service shuts down.
+type ContextHandler func(ctx context.Context, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error
+
+// AdaptGenericHandler adapts a GenericHandler to ContextHandler.
+// The GenericHandler can still get the context by e.Context().
+func AdaptGenericHandler(fn GenericHandler) ContextHandler {
+	if fn == nil {
+		return nil
+	}
+
+	return func(_ context.Context, e *sdk.GenericEvent, cfg config.Config, log *logrus.Entry) error {
+		return fn(e, cfg, log)
+	}
+}
+
 type handlers struct {
-	accessHandlers            GenericHandler
-	issueHandlers             GenericHandler
-	pullRequestHandler        GenericHandler
-	pushEventHandler          GenericHandler
-	issueCommentHandler       GenericHandler
-	reviewEventHandler        GenericHandler
-	reviewCommentEventHandler GenericHandler
+	accessHandlers            ContextHandler
+	issueHandlers             ContextHandler
+	pullRequestHandler        ContextHandler
+	pushEventHandler          ContextHandler
+	issueCommentHandler       ContextHandler
+	reviewEventHandler        ContextHandler
+	reviewCommentEventHandler ContextHandler
 }
 
 // RegisterAccessHandler registers a plugin's AnyEvent handler.
 func (h *handlers) RegisterAccessHandler(fn GenericHandler) {
-	h.accessHandlers = fn
+	h.accessHandlers = AdaptGenericHandler(fn)
 }
 
 // RegisterIssueHandler registers a plugin's IssueEvent handler.
 func (h *handlers) RegisterIssueHandler(fn GenericHandler) {
-	h.issueHandlers = fn
+	h.issueHandlers = AdaptGenericHandler(fn)
 }
 
 // RegisterPullRequestHandler registers a plugin's PullRequestEvent handler.
 func (h *handlers) RegisterPullRequestHandler(fn GenericHandler) {
-	h.pullRequestHandler = fn
+	h.pullRequestHandler = AdaptGenericHandler(fn)
 }
 
 // RegisterPushEventHandler registers a plugin's PushEvent handler.
 func (h *handlers) RegisterPushEventHandler(fn GenericHandler) {
-	h.pushEventHandler = fn
+	h.pushEventHandler = AdaptGenericHandler(fn)
 }
 
 // RegisterIssueCommentHandler registers a plugin's IssueCommentEvent handler.
 func (h *handlers) RegisterIssueCommentHandler(fn GenericHandler) {
-	h.issueCommentHandler = fn
+	h.issueCommentHandler = AdaptGenericHandler(fn)
 }
 
 // RegisterReviewEventHandler registers a plugin's ReviewEvent handler.
 func (h *handlers) RegisterReviewEventHandler(fn GenericHandler) {
-	h.reviewEventHandler = fn
+	h.reviewEventHandler = AdaptGenericHandler(fn)
 }
 
 // RegisterReviewCommentEventHandler registers a plugin's ReviewCommentEvent handler.
 func (h *handlers) RegisterReviewCommentEventHandler(fn GenericHandler) {
-	h.reviewCommentEventHandler = fn
+	h.reviewCommentEventHandler = AdaptGenericHandler(fn)
+}
+
+// RegisterContextHandler registers a plugin's ContextHandler of the event type, such as IssueEvent.
+func (h *handlers) RegisterContextHandler(eventType int, fn ContextHandler) {
+	switch eventType {
+	case AccessEvent:
+		h.accessHandlers = fn
+	case IssueEvent:
+		h.issueHandlers = fn
+	case PullRequestEvent:
+		h.pullRequestHandler = fn
+	case PushEvent:
+		h.pushEventHandler = fn
+	case IssueCommentEvent:
+		h.issueCommentHandler = fn
+	case PullRequestReviewEvent:
+		h.reviewEventHandler = fn
+	case PullRequestCommentEvent:
+		h.reviewCommentEventHandler = fn
+	}
 }
@@ -0,0 +1,99 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"community-robot-lib/config"
+	sdk "git-platform-sdk"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Harness sends webhook payloads to a robot through the dispatcher and waits for
+// them to be handled. It is used by the end-to-end tests of robots.
+type Harness struct {
+	d     *dispatcher
+	agent *config.ConfigAgent
+
+	// err is the error of the handler run by the last Send
+	mut sync.Mutex
+	err error
+}
+
+// NewHarness creates a harness of bot whose config is loaded from configFile.
+func NewHarness(bot Robot, configFile string) (*Harness, error) {
+	agent := config.NewConfigAgent(bot.NewConfig)
+	if err := agent.Start(configFile); err != nil {
+		return nil, err
+	}
+
+	subscribeConfig(bot, &agent)
+
+	h := handlers{}
+	bot.RegisterEventHandler(&h)
+
+	harness := &Harness{
+		d:     newDispatcher(&agent, h, nil, 0),
+		agent: &agent,
+	}
+
+	for i, fn := range harness.d.handlerList {
+		if fn != nil {
+			harness.d.handlerList[i] = harness.record(fn)
+		}
+	}
+
+	return harness, nil
+}
+
+// record wraps the handler to keep its error for Send.
+func (h *Harness) record(fn ContextHandler) ContextHandler {
+	return func(ctx context.Context, e *sdk.GenericEvent, c config.Config, l *logrus.Entry) error {
+		err := fn(ctx, e, c, l)
+
+		h.mut.Lock()
+		h.err = err
+		h.mut.Unlock()
+
+		return err
+	}
+}
+
+// Send posts the payload as the webhook of gitee event, such as "Issue Hook", which is
+// forwarded by the gateway, and returns the error of handler after it is handled.
+func (h *Harness) Send(eventName string, payload []byte) error {
+	h.mut.Lock()
+	h.err = nil
+	h.mut.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/gitee-hook", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitee-Event", eventName)
+	req.Header.Set(sdk.WebhookUserAgentKey, UserAgentHeader)
+
+	w := httptest.NewRecorder()
+	h.d.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		return fmt.Errorf("the webhook is rejected: %d %s", w.Code, strings.TrimSpace(w.Body.String()))
+	}
+
+	h.d.Wait()
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	return h.err
+}
+
+// Close stops the harness.
+func (h *Harness) Close() {
+	h.agent.Stop()
+	h.d.Shutdown(0)
+}
@@ -0,0 +1,118 @@
+package framework
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"community-robot-lib/utils"
+)
+
+// HealthCheck probes a dependency of robot, it returns nil if the dependency is healthy.
+type HealthCheck func() error
+
+type HealthCheckRegister interface {
+	// RegisterReadinessCheck registers a check which must pass before the robot is ready.
+	// The check runs in background by the interval and the readiness probe reads the last result.
+	// If the interval is not positive, the check is cheap and runs on each readiness probe.
+	RegisterReadinessCheck(name string, check HealthCheck, interval time.Duration)
+}
+
+var errNotProbed = errors.New("not probed yet")
+
+type healthCheck struct {
+	name     string
+	check    HealthCheck
+	interval time.Duration
+}
+
+type healthChecks struct {
+	checks []healthCheck
+
+	mut     sync.RWMutex
+	results map[string]error
+
+	timers []utils.Timer
+}
+
+func (hc *healthChecks) RegisterReadinessCheck(name string, check HealthCheck, interval time.Duration) {
+	hc.checks = append(hc.checks, healthCheck{name: name, check: check, interval: interval})
+}
+
+// start starts probing the checks which run in background.
+func (hc *healthChecks) start() {
+	hc.results = make(map[string]error, len(hc.checks))
+
+	for i := range hc.checks {
+		c := hc.checks[i]
+		if c.interval <= 0 {
+			continue
+		}
+
+		hc.setResult(c.name, errNotProbed)
+
+		probe := func() {
+			hc.setResult(c.name, c.check())
+		}
+
+		go probe()
+
+		t := utils.NewTimer()
+		t.Start(probe, c.interval, 0)
+		hc.timers = append(hc.timers, t)
+	}
+}
+
+func (hc *healthChecks) stop() {
+	for _, t := range hc.timers {
+		t.Stop()
+	}
+}
+
+func (hc *healthChecks) setResult(name string, err error) {
+	hc.mut.Lock()
+	hc.results[name] = err
+	hc.mut.Unlock()
+}
+
+// failures returns the error of each check which doesn't pass.
+func (hc *healthChecks) failures() map[string]string {
+	r := map[string]string{}
+
+	for _, c := range hc.checks {
+		var err error
+		if c.interval <= 0 {
+			err = c.check()
+		} else {
+			hc.mut.RLock()
+			err = hc.results[c.name]
+			hc.mut.RUnlock()
+		}
+
+		if err != nil {
+			r[c.name] = err.Error()
+		}
+	}
+
+	return r
+}
+
+// healthz handles the liveness probe, it passes as long as the service can respond.
+func (hc *healthChecks) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz handles the readiness probe, it fails if any readiness check doesn't pass.
+func (hc *healthChecks) readyz(w http.ResponseWriter, r *http.Request) {
+	if v := hc.failures(); len(v) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+			"status":   "not ready",
+			"failures": v,
+		})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -1,6 +1,8 @@
 package framework
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -8,7 +10,9 @@ import (
 
 	"community-robot-lib/config"
 	"community-robot-lib/interrupts"
+	"community-robot-lib/metrics"
 	"community-robot-lib/options"
+	"community-robot-lib/tracing"
 )
 
 type HandlerRegister interface {
@@ -19,6 +23,7 @@ type HandlerRegister interface {
 	RegisterIssueCommentHandler(GenericHandler)
 	RegisterReviewEventHandler(GenericHandler)
 	RegisterReviewCommentEventHandler(GenericHandler)
+	RegisterContextHandler(int, ContextHandler)
 }
 
 type Robot interface {
@@ -26,6 +31,23 @@ type Robot interface {
 	RegisterEventHandler(HandlerRegister)
 }
 
+// HealthChecker is implemented by the robot which has dependencies to probe for readiness.
+type HealthChecker interface {
+	RegisterHealthChecks(HealthCheckRegister)
+}
+
+// ConfigSubscriber is implemented by the robot which reacts to the config changes,
+// such as dropping the caches derived from the config.
+type ConfigSubscriber interface {
+	OnConfigChange(md5Sum string, c config.Config)
+}
+
+func subscribeConfig(bot Robot, agent *config.ConfigAgent) {
+	if s, ok := bot.(ConfigSubscriber); ok {
+		agent.Subscribe(s.OnConfigChange)
+	}
+}
+
 func Run(bot Robot, servOpt options.ServiceOptions, clientOpt options.ClientOptions) {
 	agent := config.NewConfigAgent(bot.NewConfig)
 	if err := agent.Start(servOpt.ConfigFile); err != nil {
@@ -33,25 +55,65 @@ func Run(bot Robot, servOpt options.ServiceOptions, clientOpt options.ClientOpti
 		return
 	}
 
+	subscribeConfig(bot, &agent)
+
+	shutdownTracing, err := tracing.Init(servOpt.TraceExporter, servOpt.TraceEndpoint)
+	if err != nil {
+		logrus.WithError(err).Error("init tracing")
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Error("shutdown tracing")
+		}
+	}()
+
 	h := handlers{}
 	bot.RegisterEventHandler(&h)
 
-	d := &dispatcher{agent: &agent, h: h, hmac: clientOpt.TokenGenerator}
-	GetClientInstance(d)
+	d := newDispatcher(&agent, h, clientOpt.TokenGenerator, servOpt.EventTimeout)
+
+	hc := &healthChecks{}
+	hc.RegisterReadinessCheck("config", func() error {
+		if _, c := agent.GetConfig(); c == nil {
+			return fmt.Errorf("config is not loaded")
+		}
+		return nil
+	}, 0)
+	hc.RegisterReadinessCheck("secrets", func() error {
+		if clientOpt.SecretsLoaded != nil && !clientOpt.SecretsLoaded() {
+			return fmt.Errorf("secrets are not loaded")
+		}
+		return nil
+	}, 0)
+	if c, ok := bot.(HealthChecker); ok {
+		c.RegisterHealthChecks(hc)
+	}
+	hc.start()
 
 	defer interrupts.WaitForGracefulShutdown()
 
 	interrupts.OnInterrupt(func() {
 		agent.Stop()
-		d.Wait()
+		hc.stop()
+		d.Shutdown(servOpt.GracePeriod)
 	})
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// service's healthy check, do nothing
 	})
 
+	http.Handle("/metrics", metrics.Handler())
+	http.HandleFunc("/healthz", hc.healthz)
+	http.HandleFunc("/readyz", hc.readyz)
+
 	http.Handle(clientOpt.HandlerPath, d)
 
+	if servOpt.AdminTokenGenerator != nil {
+		http.Handle(adminPathPrefix, newAdmin(d, servOpt.AdminTokenGenerator))
+	}
+
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(servOpt.Port)}
 
 	interrupts.ListenAndServe(httpServer, servOpt.GracePeriod)
@@ -0,0 +1,284 @@
+// Package httprecord records the http requests with their responses to fixture files,
+// and replays them in tests, so that the contracts with the remote apis can be checked
+// without network.
+package httprecord
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"community-robot-lib/utils"
+)
+
+// Censor replaces the secrets in the content, such as secret.Agent.Censor.
+type Censor func(content []byte) []byte
+
+// Interaction is a request with its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// droppedHeaders are the response headers which are not recorded.
+var droppedHeaders = []string{"Set-Cookie", "Date"}
+
+// Recorder appends the requests with the responses to the fixture file.
+// The secrets are censored before written.
+type Recorder struct {
+	censor Censor
+	w      *utils.JSONLines
+}
+
+// NewRecorder creates a recorder which appends the interactions to the file of path.
+func NewRecorder(path string, censor Censor) (*Recorder, error) {
+	w, err := utils.NewJSONLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{censor: censor, w: w}, nil
+}
+
+// Transport returns the transport which sends the requests by base and records them.
+// http.DefaultTransport is used if base is nil.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &recordingTransport{r: r, base: base}
+}
+
+type recordingTransport struct {
+	r    *Recorder
+	base http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := t.r
+
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, k := range droppedHeaders {
+		header.Del(k)
+	}
+
+	for k, vs := range header {
+		for i := range vs {
+			vs[i] = string(r.censorOf([]byte(vs[i])))
+		}
+		header[k] = vs
+	}
+
+	v := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    string(r.censorOf([]byte(req.URL.String()))),
+			Body:   string(r.censorOf(reqBody)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(r.censorOf(respBody)),
+		},
+	}
+
+	if err := r.w.Write(&v); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) censorOf(b []byte) []byte {
+	if r.censor == nil || len(b) == 0 {
+		return b
+	}
+
+	return r.censor(b)
+}
+
+// Close closes the fixture file.
+func (r *Recorder) Close() error {
+	return r.w.Close()
+}
+
+// Replayer responds the requests with the recorded responses. A request matches the
+// interaction which has the same method, url and body, and each interaction is used once.
+// The request which matches none fails.
+type Replayer struct {
+	censor Censor
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	unmatched    []string
+}
+
+// NewReplayer loads the interactions from the fixture file of path. The requests are
+// censored by censor before matching if it is not nil.
+func NewReplayer(path string, censor Censor) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var items []Interaction
+
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+
+		var v Interaction
+		if err := json.Unmarshal(s.Bytes(), &v); err != nil {
+			return nil, fmt.Errorf("invalid interaction at %s:%d, err:%w", path, line, err)
+		}
+
+		items = append(items, v)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		censor:       censor,
+		interactions: items,
+		used:         make([]bool, len(items)),
+	}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	u, b := req.URL.String(), string(body)
+	if r.censor != nil {
+		u, b = string(r.censor([]byte(u))), string(r.censor(body))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.interactions {
+		item := &r.interactions[i]
+
+		if r.used[i] || item.Request.Method != req.Method || item.Request.URL != u || item.Request.Body != b {
+			continue
+		}
+
+		r.used[i] = true
+
+		return item.Response.toHTTP(req), nil
+	}
+
+	desc := req.Method + " " + u
+	r.unmatched = append(r.unmatched, desc)
+
+	return nil, fmt.Errorf("no recorded interaction matches the request: %s", desc)
+}
+
+// Err returns the error if any request matched none of the interactions.
+func (r *Replayer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.unmatched) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("unmatched requests: %s", strings.Join(r.unmatched, ", "))
+}
+
+// Unused returns the interactions which are not requested.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var v []Interaction
+	for i := range r.interactions {
+		if !r.used[i] {
+			v = append(v, r.interactions[i])
+		}
+	}
+
+	return v
+}
+
+func (resp *Response) toHTTP(req *http.Request) *http.Response {
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// readBody reads all of the body and closes it. The body is nil if there is no content.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	defer (*body).Close()
+
+	return io.ReadAll(*body)
+}
@@ -0,0 +1,93 @@
+package httprecord
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func censorOf(secret string) Censor {
+	return func(content []byte) []byte {
+		return bytes.ReplaceAll(content, []byte(secret), []byte("CENSORED"))
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("token=SECRET body=" + string(body)))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "fixture.jsonl")
+
+	rec, err := NewRecorder(path, censorOf("SECRET"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c := &http.Client{Transport: rec.Transport(nil)}
+
+	resp, err := c.Post(srv.URL+"/labels?access_token=SECRET", "text/plain", strings.NewReader("bug"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if string(body) != "token=SECRET body=bug" {
+		t.Errorf("The recorder changed the response: %s", body)
+	}
+
+	if err = rec.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fixture, _ := os.ReadFile(path)
+	if bytes.Contains(fixture, []byte("SECRET")) {
+		t.Errorf("The secret is not censored: %s", fixture)
+	}
+
+	rp, err := NewReplayer(path, censorOf("another-secret"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	c = &http.Client{Transport: rp}
+
+	resp, err = c.Post(srv.URL+"/labels?access_token=another-secret", "text/plain", strings.NewReader("bug"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("ETag") != `"v1"` || string(body) != "token=CENSORED body=bug" {
+		t.Errorf("Unexpected replayed response: %d %v %s", resp.StatusCode, resp.Header, body)
+	}
+
+	if len(rp.Unused()) != 0 || rp.Err() != nil {
+		t.Errorf("Expected all the interactions are used")
+	}
+
+	// each interaction is used once, and the body must match
+	for _, b := range []string{"bug", "feature"} {
+		if _, err = c.Post(srv.URL+"/labels?access_token=another-secret", "text/plain", strings.NewReader(b)); err == nil {
+			t.Errorf("Expected error for the unmatched request with body %s", b)
+		}
+	}
+
+	if rp.Err() == nil {
+		t.Errorf("Expected the unmatched requests are reported")
+	}
+}
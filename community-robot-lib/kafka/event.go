@@ -1,12 +1,15 @@
 package kafka
 
 import (
+	"context"
+
 	"github.com/Shopify/sarama"
 
 	"community-robot-lib/mq"
 )
 
 type event struct {
+	ctx  context.Context
 	m    *mq.Message
 	km   *sarama.ConsumerMessage
 	err  error
@@ -31,6 +34,14 @@ func (e *event) Ack() error {
 	return nil
 }
 
+func (e *event) Context() context.Context {
+	if e.ctx != nil {
+		return e.ctx
+	}
+
+	return context.Background()
+}
+
 func (e *event) Error() error {
 	return e.err
 }
@@ -3,11 +3,14 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/Shopify/sarama"
 
+	"community-robot-lib/metrics"
 	"community-robot-lib/mq"
+	"community-robot-lib/tracing"
 	"community-robot-lib/utils"
 )
 
@@ -41,6 +44,10 @@ func (gc *groupConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 		case message := <-claim.Messages():
 			handle(message)
 
+			metrics.MQConsumerLag.WithLabelValues(
+				message.Topic, strconv.Itoa(int(message.Partition)),
+			).Set(float64(claim.HighWaterMarkOffset() - message.Offset - 1))
+
 			if gc.subOpts.AutoAck {
 				session.MarkMessage(message, "")
 			}
@@ -80,6 +87,8 @@ func (gc *groupConsumer) genHanler(session sarama.ConsumerGroupSession) func(*sa
 		}
 
 		if err := unmarshal(msg.Value, ke.m); err != nil {
+			metrics.MQConsumed.WithLabelValues(msg.Topic, metrics.Result(err)).Inc()
+
 			ke.err = fmt.Errorf("unmarshal msg failed, err: %v", err)
 			ke.m.Body = msg.Value
 
@@ -90,7 +99,20 @@ func (gc *groupConsumer) genHanler(session sarama.ConsumerGroupSession) func(*sa
 			return
 		}
 
-		if err := handler(ke); err != nil {
+		ctx, span := tracing.Tracer().Start(
+			tracing.Extract(session.Context(), ke.m.Header), "consume "+msg.Topic,
+		)
+		defer span.End()
+
+		ke.ctx = ctx
+
+		err := handler(ke)
+
+		metrics.MQConsumed.WithLabelValues(msg.Topic, metrics.Result(err)).Inc()
+
+		if err != nil {
+			span.RecordError(err)
+
 			ke.err = fmt.Errorf("handle event, err: %v", err)
 
 			if err := eh(ke); err != nil {
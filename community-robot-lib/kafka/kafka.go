@@ -11,7 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"community-robot-lib/metrics"
 	"community-robot-lib/mq"
+	"community-robot-lib/tracing"
 )
 
 var reIpPort = regexp.MustCompile(`^((25[0-5]|(2[0-4]|1\d|[1-9]|)\d)\.?\b){4}:[1-9][0-9]*$`)
@@ -117,6 +119,21 @@ func (kMQ *kfkMQ) Disconnect() error {
 
 // Publish a message to a topic in the kafka cluster.
 func (kMQ *kfkMQ) Publish(topic string, msg *mq.Message, opts ...mq.PublishOption) error {
+	opt := mq.PublishOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	if opt.Context != nil {
+		ctx, span := tracing.Tracer().Start(opt.Context, "publish "+topic)
+		defer span.End()
+
+		if msg.Header == nil {
+			msg.Header = map[string]string{}
+		}
+		tracing.Inject(ctx, msg.Header)
+	}
+
 	d, err := kMQ.opts.Codec.Marshal(msg)
 	if err != nil {
 		return err
@@ -133,6 +150,8 @@ func (kMQ *kfkMQ) Publish(topic string, msg *mq.Message, opts ...mq.PublishOptio
 
 	_, _, err = kMQ.producer.SendMessage(pm)
 
+	metrics.MQPublished.WithLabelValues(topic, metrics.Result(err)).Inc()
+
 	return err
 }
 
@@ -0,0 +1,154 @@
+// Package metrics defines the prometheus metrics shared by the framework, sdks and mq.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "robot"
+
+var (
+	// EventsReceived counts the webhook events received, by event type and org.
+	EventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "The number of webhook events received.",
+	}, []string{"event_type", "org"})
+
+	// EventsIgnored counts the events which have no handler, by event type and org.
+	EventsIgnored = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_ignored_total",
+		Help:      "The number of webhook events ignored.",
+	}, []string{"event_type", "org"})
+
+	// EventsFailed counts the events whose handler returns error, by event type and org.
+	EventsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_failed_total",
+		Help:      "The number of webhook events which failed to be handled.",
+	}, []string{"event_type", "org"})
+
+	// HandlerDuration observes the latency of handlers, by event type.
+	HandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "The latency of event handlers.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"event_type"})
+
+	// InflightHandlers is the number of handlers which are running.
+	InflightHandlers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inflight_handlers",
+		Help:      "The number of event handlers which are running.",
+	})
+
+	// PlatformAPICalls counts the calls to the platform api, by operation and status code.
+	PlatformAPICalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "platform_api_calls_total",
+		Help:      "The number of calls to the platform api.",
+	}, []string{"operation", "code"})
+
+	// PlatformAPIDuration observes the latency of the platform api, by operation.
+	PlatformAPIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "platform_api_duration_seconds",
+		Help:      "The latency of calls to the platform api.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	// SigInfoLookups counts the lookups of sig-info cache, the result is one of hit, miss and error.
+	SigInfoLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sig_info_lookups_total",
+		Help:      "The number of lookups of sig-info cache.",
+	}, []string{"operation", "result"})
+
+	// MQPublished counts the messages published to the mq, by topic and result.
+	MQPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mq_published_total",
+		Help:      "The number of messages published to the mq.",
+	}, []string{"topic", "result"})
+
+	// MQConsumed counts the messages consumed from the mq, by topic and result.
+	MQConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mq_consumed_total",
+		Help:      "The number of messages consumed from the mq.",
+	}, []string{"topic", "result"})
+
+	// MQConsumerLag is the number of messages behind the latest offset, by topic and partition.
+	MQConsumerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mq_consumer_lag",
+		Help:      "The number of messages which are not consumed yet.",
+	}, []string{"topic", "partition"})
+
+	// ConfigReloads counts the reloads of config which is changed, by result.
+	ConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "The number of reloads of the changed config.",
+	}, []string{"result"})
+
+	// ConfigLastReloadSuccessful is 1 if the last reload succeeded, and 0 if the config is rejected.
+	ConfigLastReloadSuccessful = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_successful",
+		Help:      "Whether the last reload of config succeeded.",
+	})
+
+	// ConfigInfo is always 1, its label md5 is the md5 of the config in use.
+	ConfigInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_info",
+		Help:      "The md5 of the config in use.",
+	}, []string{"md5"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		EventsReceived,
+		EventsIgnored,
+		EventsFailed,
+		HandlerDuration,
+		InflightHandlers,
+		PlatformAPICalls,
+		PlatformAPIDuration,
+		SigInfoLookups,
+		MQPublished,
+		MQConsumed,
+		MQConsumerLag,
+		ConfigReloads,
+		ConfigLastReloadSuccessful,
+		ConfigInfo,
+	)
+}
+
+// Handler returns the handler which exposes the metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObservePlatformAPICall records a call to the platform api. The code is 0 if there is no response.
+func ObservePlatformAPICall(operation string, code int, duration time.Duration) {
+	PlatformAPICalls.WithLabelValues(operation, strconv.Itoa(code)).Inc()
+	PlatformAPIDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// Result converts the error to the result label.
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "success"
+}
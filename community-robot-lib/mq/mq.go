@@ -14,6 +14,8 @@ limitations under the License.
 // Package mq is an interface used for asynchronous messaging, the default implementation is kafka
 package mq
 
+import "context"
+
 type MQ interface {
 	Init(...Option) error
 	Options() Options
@@ -37,6 +39,8 @@ type Event interface {
 	Error() error
 	// Extra the important information other than the message body
 	Extra() map[string]interface{}
+	// Context return the context carrying the trace context read from the message header
+	Context() context.Context
 }
 
 // Subscriber is a convenience return type for the Subscribe method.
@@ -2,17 +2,30 @@ package options
 
 import (
 	"flag"
+	"fmt"
 )
 
 // ClientOptions holds options for interacting with Client.
 type ClientOptions struct {
-	TokenPath       string
-	TokenGenerator  func() []byte
+	TokenPath      string
+	TokenGenerator func() []byte
+	// SecretsLoaded reports whether the secrets, such as the token of platform, are loaded,
+	// it is checked by the readiness probe
+	SecretsLoaded   func() bool
 	RepoCacheDir    string
 	CacheRepoOnPV   bool
 	HandlerPath     string
 	CacheEndpoint   string
 	CacheMaxRetries int
+
+	// APIQPS, APIBurst and APIMaxRetries limit the calls to the platform api
+	APIQPS        float64
+	APIBurst      int
+	APIMaxRetries int
+
+	// RecordDir is the directory to record the requests to the platform and sig-info-cache
+	// with the responses as the fixtures of contract tests
+	RecordDir string
 }
 
 // NewClientOptions creates a ClientOptions with default values.
@@ -39,9 +52,17 @@ func (o *ClientOptions) addFlags(defaultClientTokenPath string, fs *flag.FlagSet
 		defaultClientTokenPath,
 		"Path to the file containing the Client OAuth secret.",
 	)
+	fs.Float64Var(&o.APIQPS, "api-qps", 10, "The number of calls per second to the platform api with a token, no limit if it is negative.")
+	fs.IntVar(&o.APIBurst, "api-burst", 20, "The maximum number of calls at once to the platform api with a token.")
+	fs.StringVar(&o.RecordDir, "record-dir", "", "The directory to record the requests to the platform and sig-info-cache with the responses, the secrets are censored.")
+	fs.IntVar(&o.APIMaxRetries, "api-max-retries", 3, "The maximum number of retries of a platform api call which responds with 429, or 5xx if it is idempotent. No retry if it is negative.")
 }
 
 // Validate validates Client options.
 func (o *ClientOptions) Validate() error {
+	if o.APIBurst < 0 {
+		return fmt.Errorf("api-burst can not be negative")
+	}
+
 	return nil
 }
@@ -10,6 +10,28 @@ type ServiceOptions struct {
 	Port        int
 	ConfigFile  string
 	GracePeriod time.Duration
+
+	// EventTimeout is the deadline of handling an event, no deadline if it is not positive
+	EventTimeout time.Duration
+
+	// DryRun means all the write calls to the platform are only recorded
+	DryRun bool
+
+	// DryRunSink is the path of file to record the intercepted calls in json lines
+	DryRunSink string
+
+	// AdminTokenPath is the path of file containing the token of admin api.
+	// The admin api is disabled if it is empty.
+	AdminTokenPath string
+
+	// AdminTokenGenerator returns the token of admin api
+	AdminTokenGenerator func() []byte
+
+	// TraceExporter is the exporter of tracing, it is one of otlp, stdout or empty which disables tracing
+	TraceExporter string
+
+	// TraceEndpoint is the host:port of OTLP http receiver
+	TraceEndpoint string
 }
 
 func (o *ServiceOptions) Validate() error {
@@ -24,4 +46,10 @@ func (o *ServiceOptions) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.Port, "port", 8888, "Port to listen on.")
 	fs.StringVar(&o.ConfigFile, "config-file", "", "Path to config file.")
 	fs.DurationVar(&o.GracePeriod, "grace-period", 180*time.Second, "On shutdown, try to handle remaining events for the specified duration.")
+	fs.DurationVar(&o.EventTimeout, "event-timeout", 5*time.Minute, "The deadline of handling an event, no deadline if it is not positive.")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "Log the write calls to the platform instead of sending them.")
+	fs.StringVar(&o.DryRunSink, "dry-run-sink", "", "Path to the json lines file which records the write calls intercepted in dry run mode.")
+	fs.StringVar(&o.TraceExporter, "trace-exporter", "", "The exporter of tracing, it is one of otlp, stdout or empty which disables tracing.")
+	fs.StringVar(&o.TraceEndpoint, "trace-endpoint", "", "The host:port of OTLP http receiver used by the otlp trace exporter.")
+	fs.StringVar(&o.AdminTokenPath, "admin-token-path", "", "Path to the file containing the token of admin api, the admin api is disabled if it is not set.")
 }
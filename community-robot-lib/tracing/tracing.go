@@ -0,0 +1,88 @@
+// Package tracing sets up the OpenTelemetry tracing shared by the framework, sdks and mq.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	instrumentationName = "community-robot-lib"
+)
+
+// Init sets up the global tracer provider which exports spans by the exporter.
+// The endpoint is the host:port of OTLP http receiver which is only used by the otlp exporter.
+// It also instruments http.DefaultTransport, so all the http calls made by the default
+// transport create spans and propagate the trace context.
+// The returned function flushes and stops the exporter.
+func Init(exporter, endpoint string) (func(context.Context) error, error) {
+	var e sdktrace.SpanExporter
+	var err error
+
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithInsecure()}
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		e, err = otlptracehttp.New(context.Background(), opts...)
+
+	case ExporterStdout:
+		e, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	default:
+		err = fmt.Errorf("unknown trace exporter: %s", exporter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(e))
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	http.DefaultTransport = otelhttp.NewTransport(http.DefaultTransport)
+
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer used by the robots.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Inject writes the trace context of ctx to the headers.
+func Inject(ctx context.Context, headers map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// Extract returns a context carrying the trace context read from the headers.
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// ExtractHTTP returns a context carrying the trace context read from the http headers.
+func ExtractHTTP(ctx context.Context, h http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(h))
+}
@@ -89,8 +89,15 @@ func (hc *HttpClient) do(req *http.Request) (resp *http.Response, err error) {
 	maxRetries := hc.MaxRetries
 	backoff := 10 * time.Millisecond
 
+	// stop retrying once the request is cancelled or its deadline exceeds
+	ctx := req.Context()
+
 	for retries := 1; retries < maxRetries; retries++ {
-		time.Sleep(backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
 		backoff *= 2
 
 		if resp, err = hc.Client.Do(req); err == nil {
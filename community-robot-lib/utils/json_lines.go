@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// JSONLines appends values to a file in json lines format.
+// It is safe for concurrent use.
+type JSONLines struct {
+	mut sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+func NewJSONLines(path string) (*JSONLines, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+
+	return &JSONLines{f: f, enc: enc}, nil
+}
+
+// Write writes v as a single line.
+func (j *JSONLines) Write(v interface{}) error {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+
+	return j.enc.Encode(v)
+}
+
+func (j *JSONLines) Close() error {
+	return j.f.Close()
+}
@@ -2,28 +2,48 @@ package main
 
 import (
 	"community-robot-lib/config"
+	"community-robot-lib/utils"
 	"fmt"
+	sdk "git-platform-sdk"
+	"github.com/sirupsen/logrus"
+	"sync"
 )
 
+// configuration is layered. The config of a repo is merged field by field from the defaults,
+// the item for its org, the item whose glob or regular expression matches it and the item
+// for it exactly, the later one overrides the former one.
 type configuration struct {
+	// Defaults are the fields shared by all the config items, it can't have repos or excluded_repos
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+
 	ConfigItems []botConfig `json:"config_items,omitempty"`
+
+	// rawItems are the fields which each config item sets explicitly
+	rawItems []map[string]interface{}
+
+	// effective caches the merged config of each repo
+	effective sync.Map
 }
 
-func (c *configuration) configFor(org, repo string) *botConfig {
+// configFor returns the merged config of org/repo, it is nil if no config item applies to it.
+func (c *configuration) configFor(org, repo string) (*botConfig, error) {
 	if c == nil {
-		return nil
+		return nil, nil
 	}
 
-	items := c.ConfigItems
-	v := make([]config.IRepoFilter, len(items))
-	for i := range items {
-		v[i] = &items[i]
+	key := org + "/" + repo
+	if v, ok := c.effective.Load(key); ok {
+		return v.(*botConfig), nil
 	}
 
-	if i := config.Find(org, repo, v); i >= 0 {
-		return &items[i]
+	bc, _, err := c.effectiveConfig(org, repo)
+	if err != nil || bc == nil {
+		return nil, err
 	}
-	return nil
+
+	v, _ := c.effective.LoadOrStore(key, bc)
+
+	return v.(*botConfig), nil
 }
 
 func (c *configuration) Validate() error {
@@ -31,26 +51,32 @@ func (c *configuration) Validate() error {
 		return nil
 	}
 
-	items := c.ConfigItems
-	for i := range items {
-		if err := items[i].validate(); err != nil {
-			return err
+	mErr := utils.NewMultiErrors()
+
+	for k := range c.Defaults {
+		if filterKeys.Has(k) {
+			mErr.Add(fmt.Sprintf("defaults.%s: is not allowed", k))
 		}
 	}
-	return nil
-}
 
-func (c *configuration) SetDefault() {
-	if c == nil {
-		return
+	items := c.ConfigItems
+	filters := make([]config.RepoFilter, len(items))
+	for i := range items {
+		mErr.AddError(c.validateItem(i))
+
+		filters[i] = items[i].RepoFilter
 	}
 
-	Items := c.ConfigItems
-	for i := range Items {
-		Items[i].setDefault()
+	for _, v := range config.AmbiguousFilters(filters) {
+		logrus.Warn(v)
 	}
+
+	return mErr.Err()
 }
 
+// SetDefault does nothing, since the default values are set to the merged config of each repo.
+func (c *configuration) SetDefault() {}
+
 type botConfig struct {
 	config.RepoFilter
 
@@ -58,7 +84,7 @@ type botConfig struct {
 	CommunityName string `json:"community_name" required:"true"`
 
 	// CommandLink is the link to command help document page.
-	CommandLink string `json:"command_link" required:"true"`
+	CommandLink string `json:"command_link" required:"true" format:"url"`
 
 	// CommunityRepo is used to read file path
 	CommunityRepo string `json:"community_repo" required:"true"`
@@ -81,29 +107,105 @@ type botConfig struct {
 	// NoNeedToNotice means that no need to @ maintainers and committers in welcome message
 	NoNeedToNotice bool `json:"no_need_to_notice,omitempty"`
 
+	// RemoveStaleSigLabel means to remove the sig labels which don't belong to the current sig of repo
+	RemoveStaleSigLabel bool `json:"remove_stale_sig_label,omitempty"`
+
+	// DryRun means to log the write calls to the platform instead of sending them
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// RepoLabels are the labels which the repo should have, they are synchronized when an event of repo comes
+	RepoLabels []sdk.Label `json:"repo_labels,omitempty"`
+
+	// PruneRepoLabels means to delete the labels of repo which are not in RepoLabels,
+	// except the sig label and the labels added by robot
+	PruneRepoLabels bool `json:"prune_repo_labels,omitempty"`
+
+	// WelcomeActions are the actions of issue and PR which trigger the welcome message, default: opened
+	WelcomeActions []string `json:"welcome_actions,omitempty"`
+
+	// RelabelActions are the actions of issue and PR which trigger the sig labelling,
+	// default: opened and transferred
+	RelabelActions []string `json:"relabel_actions,omitempty"`
+
+	// ReassignActions are the actions of issue and PR which trigger assigning the maintainers if need_assign is set,
+	// default: opened
+	ReassignActions []string `json:"reassign_actions,omitempty"`
+
+	// Filter decides which issues and PRs to skip
+	Filter eventFilter `json:"filter,omitempty"`
+
 	// reposSig is used to cache information
 	reposSig map[string]string
 }
 
 func (c *botConfig) setDefault() {
+	c.Filter.setDefault()
+
+	if len(c.WelcomeActions) == 0 {
+		c.WelcomeActions = []string{sdk.ActionOpened}
+	}
+
+	if len(c.RelabelActions) == 0 {
+		c.RelabelActions = []string{sdk.ActionOpened, sdk.ActionTransferred}
+	}
+
+	if len(c.ReassignActions) == 0 {
+		c.ReassignActions = []string{sdk.ActionOpened}
+	}
 }
 
-func (c *botConfig) validate() error {
-	if c.CommunityName == "" {
-		return fmt.Errorf("the community_name configuration can not be empty")
+// triggers reports whether the action, which is normalized, is one of actions.
+func triggers(actions []string, action string) bool {
+	for _, v := range actions {
+		if v == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handles reports whether the action of issue or PR triggers any work.
+func (c *botConfig) handles(action string) bool {
+	return triggers(c.WelcomeActions, action) || triggers(c.RelabelActions, action) ||
+		(c.NeedAssign && triggers(c.ReassignActions, action))
+}
+
+// validate checks the config whose path in the config file is path, and reports all the violations.
+func (c *botConfig) validate(path string) error {
+	mErr := utils.NewMultiErrors()
+	mErr.AddError(config.ValidateStruct(c, path))
+
+	if err := c.Filter.validate(); err != nil {
+		mErr.Add(fmt.Sprintf("%s.filter: %v", path, err))
+	}
+
+	for _, v := range []struct {
+		name    string
+		actions []string
+	}{
+		{"welcome_actions", c.WelcomeActions},
+		{"relabel_actions", c.RelabelActions},
+		{"reassign_actions", c.ReassignActions},
+	} {
+		for _, action := range v.actions {
+			if !sdk.IsNormalizedAction(action) {
+				mErr.Add(fmt.Sprintf("%s.%s: %q is not a normalized action", path, v.name, action))
+			}
+		}
 	}
 
-	if c.CommandLink == "" {
-		return fmt.Errorf("the command_link configuration can not be empty")
+	if err := sdk.ValidateLabels(c.RepoLabels); err != nil {
+		mErr.Add(fmt.Sprintf("%s.repo_labels: %v", path, err))
 	}
 
-	if c.CommunityRepo == "" {
-		return fmt.Errorf("the community_repo configuration can not be empty")
+	if c.PruneRepoLabels && len(c.RepoLabels) == 0 {
+		mErr.Add(fmt.Sprintf("%s.repo_labels: can not be empty when prune_repo_labels is set", path))
 	}
 
-	if c.Branch == "" {
-		return fmt.Errorf("the branch configuration can not be empty")
+	if err := c.RepoFilter.Validate(); err != nil {
+		mErr.Add(fmt.Sprintf("%s: %v", path, err))
 	}
 
-	return c.RepoFilter.Validate()
+	return mErr.Err()
 }
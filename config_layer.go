@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"community-robot-lib/config"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	sourceDefaults = "defaults"
+	sourceBuiltIn  = "built-in default"
+)
+
+// the keys of RepoFilter which are not merged, the effective config takes the ones of the most specific item
+var filterKeys = sets.NewString("repos", "excluded_repos")
+
+// configLayer is the raw values of defaults or a config item which are merged to the effective config.
+type configLayer struct {
+	name   string
+	values map[string]interface{}
+}
+
+// mergedConfig is the effective config of a repo with the source of each field.
+type mergedConfig struct {
+	// layers are the names of layers which are merged
+	layers []string
+
+	values map[string]interface{}
+
+	// sources maps the path of field, such as filter.skip_bots, to the layer which sets it
+	sources map[string]string
+}
+
+func (c *configuration) UnmarshalJSON(b []byte) error {
+	type plain configuration
+
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		ConfigItems []map[string]interface{} `json:"config_items"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	c.rawItems = raw.ConfigItems
+
+	return nil
+}
+
+// layersFor returns the layers which apply to org/repo from the least specific one,
+// they are the defaults and the first matched item of each match level.
+func (c *configuration) layersFor(org, repo string) []configLayer {
+	byLevel := map[int]int{}
+	for i := range c.ConfigItems {
+		level := c.ConfigItems[i].Match(org, repo)
+		if _, ok := byLevel[level]; !ok && level != config.MatchNone {
+			byLevel[level] = i
+		}
+	}
+
+	if len(byLevel) == 0 {
+		return nil
+	}
+
+	layers := []configLayer{c.defaultsLayer()}
+	for _, level := range []int{config.MatchOrg, config.MatchPattern, config.MatchExact} {
+		if i, ok := byLevel[level]; ok {
+			layers = append(layers, c.itemLayer(i))
+		}
+	}
+
+	return layers
+}
+
+func (c *configuration) defaultsLayer() configLayer {
+	return configLayer{name: sourceDefaults, values: c.Defaults}
+}
+
+func (c *configuration) itemLayer(i int) configLayer {
+	l := configLayer{name: fmt.Sprintf("config_items[%d]", i)}
+	if i < len(c.rawItems) {
+		l.values = c.rawItems[i]
+	}
+
+	return l
+}
+
+// mergeLayers merges the layers field by field, the later one overrides the former one.
+// A field which is set to null is unset, so that it falls back to the built-in default.
+func mergeLayers(layers []configLayer) mergedConfig {
+	m := mergedConfig{
+		values:  map[string]interface{}{},
+		sources: map[string]string{},
+	}
+
+	for _, l := range layers {
+		m.layers = append(m.layers, l.name)
+
+		for k, v := range l.values {
+			if !filterKeys.Has(k) {
+				m.merge(m.values, k, v, k, l.name)
+			}
+		}
+	}
+
+	return m
+}
+
+func (m *mergedConfig) merge(dst map[string]interface{}, k string, v interface{}, path, source string) {
+	if v == nil {
+		delete(dst, k)
+		m.unsetSources(path)
+
+		return
+	}
+
+	src, isObject := v.(map[string]interface{})
+	if cur, ok := dst[k].(map[string]interface{}); ok && isObject {
+		for sk, sv := range src {
+			m.merge(cur, sk, sv, path+"."+sk, source)
+		}
+
+		return
+	}
+
+	m.unsetSources(path)
+
+	if isObject {
+		// copy it, so that the layer is not modified by the following merging
+		cp := map[string]interface{}{}
+		for sk, sv := range src {
+			m.merge(cp, sk, sv, path+"."+sk, source)
+		}
+
+		dst[k] = cp
+
+		return
+	}
+
+	dst[k] = v
+	m.sources[path] = source
+}
+
+func (m *mergedConfig) unsetSources(path string) {
+	for k := range m.sources {
+		if k == path || strings.HasPrefix(k, path+".") {
+			delete(m.sources, k)
+		}
+	}
+}
+
+// toBotConfig decodes the merged values to the config which applies to the repos of filter,
+// path is the path of the most specific layer in the config file.
+func (m *mergedConfig) toBotConfig(filter config.RepoFilter, path string) (*botConfig, error) {
+	b, err := json.Marshal(m.values)
+	if err != nil {
+		return nil, err
+	}
+
+	bc := new(botConfig)
+	if err := json.Unmarshal(b, bc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	bc.RepoFilter = filter
+	bc.setDefault()
+
+	return bc, bc.validate(path)
+}
+
+// effectiveConfig merges the layers which apply to org/repo, it is nil if no item matches.
+func (c *configuration) effectiveConfig(org, repo string) (*botConfig, *mergedConfig, error) {
+	layers := c.layersFor(org, repo)
+	if len(layers) == 0 {
+		return nil, nil, nil
+	}
+
+	// the last layer is the most specific item
+	filter := c.ConfigItems[config.Find(org, repo, c.filters())].RepoFilter
+
+	m := mergeLayers(layers)
+	bc, err := m.toBotConfig(filter, layers[len(layers)-1].name)
+
+	return bc, &m, err
+}
+
+func (c *configuration) filters() []config.IRepoFilter {
+	v := make([]config.IRepoFilter, len(c.ConfigItems))
+	for i := range c.ConfigItems {
+		v[i] = &c.ConfigItems[i]
+	}
+
+	return v
+}
+
+// validateItem validates each item merged with the defaults, and with the item of its org
+// if the item is for some repos of the org. Only the violations of the first invalid entry
+// of repos are reported, since the other entries mostly have the same ones.
+func (c *configuration) validateItem(i int) error {
+	item := &c.ConfigItems[i]
+	l := c.itemLayer(i)
+
+	if len(item.Repos) == 0 {
+		m := mergeLayers([]configLayer{c.defaultsLayer(), l})
+		_, err := m.toBotConfig(item.RepoFilter, l.name)
+
+		return err
+	}
+
+	for _, entry := range item.Repos {
+		layers := []configLayer{c.defaultsLayer()}
+
+		if org, _, ok := strings.Cut(entry, "/"); ok {
+			if j := c.orgItem(org); j >= 0 {
+				layers = append(layers, c.itemLayer(j))
+			}
+		}
+
+		m := mergeLayers(append(layers, l))
+		if _, err := m.toBotConfig(item.RepoFilter, l.name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orgItem returns the index of item which has org in its repos, it is -1 if not found.
+func (c *configuration) orgItem(org string) int {
+	for i := range c.ConfigItems {
+		if sets.NewString(c.ConfigItems[i].Repos...).Has(org) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// explain writes the effective config of org/repo, each field is followed by where it comes from.
+func (c *configuration) explain(w io.Writer, org, repo string) error {
+	bc, m, err := c.effectiveConfig(org, repo)
+	if err != nil {
+		return err
+	}
+
+	if bc == nil {
+		return fmt.Errorf("no config for this repo:%s/%s", org, repo)
+	}
+
+	// the effective values include the built-in defaults, and the merged values include
+	// the fields which are set to zero values explicitly.
+	b, err := json.Marshal(bc)
+	if err != nil {
+		return err
+	}
+
+	var effective map[string]interface{}
+	if err := json.Unmarshal(b, &effective); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{}
+	flatten(m.values, "", fields)
+	flatten(effective, "", fields)
+
+	for _, k := range filterKeys.List() {
+		delete(fields, k)
+	}
+
+	paths := make([]string, 0, len(fields))
+	for k := range fields {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	fmt.Fprintf(w, "# the effective config of %s/%s, merged from %s\n", org, repo, strings.Join(m.layers, ", "))
+
+	for _, path := range paths {
+		v, err := json.Marshal(fields[path])
+		if err != nil {
+			return err
+		}
+
+		source, ok := m.sources[path]
+		if !ok {
+			source = sourceBuiltIn
+		}
+
+		fmt.Fprintf(w, "%s: %s\t# %s\n", path, v, source)
+	}
+
+	return nil
+}
+
+// flatten puts the leaf fields of values to fields by their paths.
+func flatten(values map[string]interface{}, prefix string, fields map[string]interface{}) {
+	for k, v := range values {
+		path := prefix + k
+
+		if sub, ok := v.(map[string]interface{}); ok {
+			flatten(sub, path+".", fields)
+		} else {
+			fields[path] = v
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testLayeredConfig = "testdata/layered_config.yaml"
+
+func TestLayeredConfig(t *testing.T) {
+	cfg, err := loadConfig(testLayeredConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		repo            string
+		needAssign      bool
+		removeStale     bool
+		skipDrafts      bool
+		welcomeActions  int
+		commandLinkFrom string
+	}{
+		{repo: "community", needAssign: true, welcomeActions: 2},
+		{repo: "kernel-rt", needAssign: true, removeStale: true, welcomeActions: 2},
+		{repo: "kernel", removeStale: true, skipDrafts: true, welcomeActions: 1},
+	}
+
+	for _, c := range cases {
+		bc, err := cfg.configFor("openeuler", c.repo)
+		if err != nil || bc == nil {
+			t.Fatalf("%s: expected config, got %v, %v", c.repo, bc, err)
+		}
+
+		if bc.CommunityName != "openEuler" || !bc.Filter.SkipBots || len(bc.Filter.SkipAuthors) != 1 {
+			t.Errorf("%s: the defaults and the org item are not merged: %+v", c.repo, bc)
+		}
+
+		if bc.NeedAssign != c.needAssign || bc.RemoveStaleSigLabel != c.removeStale ||
+			bc.Filter.SkipDrafts != c.skipDrafts || len(bc.WelcomeActions) != c.welcomeActions {
+			t.Errorf("%s: unexpected config: %+v", c.repo, bc)
+		}
+	}
+
+	if bc, err := cfg.configFor("src-openeuler", "kernel"); err != nil || bc != nil {
+		t.Errorf("expected no config, got %v, %v", bc, err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.explain(&out, "openeuler", "kernel"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"merged from defaults, config_items[0], config_items[1], config_items[2]",
+		"community_name: \"openEuler\"\t# defaults",
+		"need_assign: false\t# config_items[2]",
+		"filter.skip_bots: true\t# config_items[0]",
+		"filter.skip_drafts: true\t# config_items[2]",
+		"remove_stale_sig_label: true\t# config_items[1]",
+		"welcome_actions: [\"opened\"]\t# built-in default",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the explanation:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLayeredConfigValidate(t *testing.T) {
+	cfg := &configuration{}
+	if err := cfg.UnmarshalJSON([]byte(`{
+		"config_items": [
+			{"repos": ["openeuler"], "community_name": "openEuler"},
+			{"repos": ["openeuler/kernel"], "community_repo": "community", "branch": "master", "welcome_actions": ["open"]}
+		]
+	}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err := cfg.Validate()
+	for _, want := range []string{
+		"config_items[0].command_link: is required",
+		"config_items[0].branch: is required",
+		"config_items[1].command_link: is required",
+		`config_items[1].welcome_actions: "open" is not a normalized action`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+
+	cfg.Defaults = map[string]interface{}{"repos": []interface{}{"openeuler"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error of repos in defaults")
+	}
+}
+
+func TestAmbiguousConfigLoads(t *testing.T) {
+	for _, file := range []string{testConfig, testLayeredConfig} {
+		if _, err := loadConfig(file); err != nil {
+			t.Errorf("%s: unexpected error: %v", file, err)
+		}
+	}
+
+	// the repo listed by two items and the overlapping patterns are allowed as before
+	cfg := &configuration{}
+	if err := cfg.UnmarshalJSON([]byte(`{
+		"defaults": {
+			"community_name": "openEuler", "community_repo": "community", "branch": "master",
+			"command_link": "https://gitee.com/openeuler/community/blob/master/en/sig-infrastructure/command.md"
+		},
+		"config_items": [
+			{"repos": ["openeuler/kernel", "openeuler/docs-*"]},
+			{"repos": ["openeuler/kernel", "openeuler/docs"], "need_assign": true}
+		]
+	}`)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
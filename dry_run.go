@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type dryRunAction struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Payload any       `json:"payload"`
+}
+
+// newDryRunRecorder returns a recorder which logs the intercepted write calls
+// and writes them to the sink if it is not nil.
+func newDryRunRecorder(sink *utils.JSONLines) func(action string, payload any) {
+	log := logrus.WithField("mode", "dry-run")
+
+	return func(action string, payload any) {
+		log.WithField("payload", payload).Infof("intercepted %s", action)
+
+		if sink == nil {
+			return
+		}
+
+		if err := sink.Write(dryRunAction{
+			Time:    time.Now(),
+			Action:  action,
+			Payload: payload,
+		}); err != nil {
+			log.WithError(err).Error("write dry run sink")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	sdk "git-platform-sdk"
+)
+
+// eventFilter decides which issues and PRs the robot skips, it is evaluated before any platform call.
+type eventFilter struct {
+	// SkipAuthors are the glob patterns of authors to skip, such as "*-bot"
+	SkipAuthors []string `json:"skip_authors,omitempty"`
+
+	// SkipBots means to skip the bot accounts which are marked by the payload
+	SkipBots bool `json:"skip_bots,omitempty"`
+
+	// SkipDrafts means to skip the draft PRs
+	SkipDrafts bool `json:"skip_drafts,omitempty"`
+
+	// Branches are the glob patterns of target branches of PR to handle, all the branches if empty
+	Branches []string `json:"branches,omitempty"`
+
+	// SkipBranches are the glob patterns of target branches of PR to skip, it takes precedence over Branches
+	SkipBranches []string `json:"skip_branches,omitempty"`
+
+	// SkipTitles are the regular expressions of titles to skip, such as "^build\\(deps\\)"
+	SkipTitles []string `json:"skip_titles,omitempty"`
+
+	// titles are the compiled SkipTitles, they live as long as the config
+	titles []*regexp.Regexp
+}
+
+// setDefault compiles SkipTitles, the invalid ones are reported by validate.
+func (f *eventFilter) setDefault() {
+	f.titles = make([]*regexp.Regexp, 0, len(f.SkipTitles))
+	for _, v := range f.SkipTitles {
+		if r, err := regexp.Compile(v); err == nil {
+			f.titles = append(f.titles, r)
+		}
+	}
+}
+
+func (f *eventFilter) validate() error {
+	for _, patterns := range [][]string{f.SkipAuthors, f.Branches, f.SkipBranches} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q, %w", p, err)
+			}
+		}
+	}
+
+	for _, v := range f.SkipTitles {
+		if _, err := regexp.Compile(v); err != nil {
+			return fmt.Errorf("invalid regular expression of skip_titles %q, %w", v, err)
+		}
+	}
+
+	return nil
+}
+
+// filterTarget is what the filter checks about an issue or PR.
+type filterTarget struct {
+	author string
+	bot    bool
+	title  string
+
+	// the fields below are of PR only
+	isPR   bool
+	draft  bool
+	branch string
+}
+
+// newFilterTarget gets the target from the typed event, and falls back to the flat fields
+// of event which has no payload, such as the one replayed by the admin api.
+func newFilterTarget(e *sdk.GenericEvent, isPR bool) filterTarget {
+	if isPR {
+		t := filterTarget{author: e.PRAuthor, isPR: true}
+
+		if v, err := e.AsPullRequestEvent(); err == nil {
+			pr := &v.PullRequest
+			t.author, t.bot, t.title = pr.User.Login, pr.User.IsBot(), pr.Title
+			t.draft, t.branch = pr.Draft, pr.Base.Ref
+		}
+
+		return t
+	}
+
+	t := filterTarget{author: e.IssueAuthor}
+
+	if v, err := e.AsIssueEvent(); err == nil {
+		iss := &v.Issue
+		t.author, t.bot, t.title = iss.User.Login, iss.User.IsBot(), iss.Title
+	}
+
+	return t
+}
+
+// skipReason returns why the target is skipped, it is empty if the target should be handled.
+func (f *eventFilter) skipReason(t filterTarget) string {
+	if f.SkipBots && t.bot {
+		return fmt.Sprintf("the author %s is a bot", t.author)
+	}
+
+	if p := matchGlob(f.SkipAuthors, t.author); p != "" {
+		return fmt.Sprintf("the author %s matches %s of skip_authors", t.author, p)
+	}
+
+	for _, r := range f.titles {
+		if r.MatchString(t.title) {
+			return fmt.Sprintf("the title matches %s of skip_titles", r)
+		}
+	}
+
+	if !t.isPR {
+		return ""
+	}
+
+	if f.SkipDrafts && t.draft {
+		return "the PR is a draft"
+	}
+
+	// the branch is unknown if the event has no payload
+	if t.branch == "" {
+		return ""
+	}
+
+	if p := matchGlob(f.SkipBranches, t.branch); p != "" {
+		return fmt.Sprintf("the target branch %s matches %s of skip_branches", t.branch, p)
+	}
+
+	if len(f.Branches) > 0 && matchGlob(f.Branches, t.branch) == "" {
+		return fmt.Sprintf("the target branch %s matches none of branches", t.branch)
+	}
+
+	return ""
+}
+
+// matchGlob returns the first pattern which s matches.
+func matchGlob(patterns []string, s string) string {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return p
+		}
+	}
+
+	return ""
+}
@@ -1,29 +1,188 @@
 package sdkadapter
 
 import (
-	"context"
+	"fmt"
+	"github.com/antihax/optional"
 	"github.com/opensourceways/go-gitee/gitee"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
 func (c *ClientTarget) AddIssueComment(iss *IssueParameter) error {
-	opt := gitee.PullRequestCommentPostParam{Body: iss.Comment}
-	number, _ := strconv.ParseInt(iss.Number, 10, 32)
-	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
-		context.Background(), iss.Org, iss.Repo, int32(number), opt)
-	return formatErr(err, "create comment of pr")
+	resp, err := c.call("AddIssueComment", http.MethodPost, fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/%s/comments", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.Number),
+	), map[string]any{"body": iss.Comment}, nil)
+
+	return formatErr(err, resp, "create comment of issue")
+}
+
+func (c *ClientTarget) AssignIssue(iss *IssueParameter) error {
+	if len(iss.Reviewers) == 0 {
+		return nil
+	}
+
+	opt := gitee.IssueUpdateParam{
+		Repo:     iss.Repo,
+		Assignee: iss.Reviewers[0],
+	}
+	if len(iss.Reviewers) > 1 {
+		opt.Collaborators = strings.Join(iss.Reviewers[1:], ",")
+	}
+
+	_, resp, err := c.ac.IssuesApi.PatchV5ReposOwnerIssuesNumber(c.opContext("AssignIssue"), iss.Org, iss.Number, opt)
+	return formatErr(err, resp, "assign issue")
 }
 
 func (c *ClientTarget) DeleteIssueComment(iss *IssueParameter) error {
-	var id int32
-	if len(iss.CommentID) > 0 {
-		i, e := strconv.Atoi(iss.CommentID)
-		if e != nil {
-			id = int32(i)
+	resp, err := c.call("DeleteIssueComment", http.MethodDelete, fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/comments/%s", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.CommentID),
+	), nil, nil)
+
+	return formatErr(err, resp, "delete comment of issue")
+}
+
+func (c *ClientTarget) ListOpenIssues(org, repo string) ([]string, error) {
+	var r []string
+
+	opt := gitee.GetV5ReposOwnerRepoIssuesOpts{State: optional.NewString("open")}
+	resp, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		opt.Page = optional.NewInt32(page)
+		opt.PerPage = optional.NewInt32(size)
+
+		issues, resp, err := c.ac.IssuesApi.GetV5ReposOwnerRepoIssues(c.opContext("ListOpenIssues"), org, repo, &opt)
+		for i := range issues {
+			r = append(r, issues[i].Number)
+		}
+
+		return len(issues), resp, err
+	})
+	if err != nil {
+		return nil, formatErr(err, resp, "list open issues")
+	}
+
+	return r, nil
+}
+
+func (c *ClientTarget) GetIssue(iss *IssueParameter) (*Issue, error) {
+	var v giteeIssue
+	resp, err := c.get("GetIssue", fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/%s", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.Number),
+	), &v)
+	if err != nil {
+		return nil, formatErr(err, resp, "get issue")
+	}
+
+	return v.toIssue(), nil
+}
+
+func (c *ClientTarget) ListIssueComments(iss *IssueParameter) ([]Comment, error) {
+	r, err := c.listComments("ListIssueComments", fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/%s/comments", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.Number),
+	))
+
+	return r, formatErr(err, nil, "list comments of issue")
+}
+
+// UpdateIssueComment changes the comment of iss.CommentID to iss.Comment.
+func (c *ClientTarget) UpdateIssueComment(iss *IssueParameter) error {
+	resp, err := c.patch("UpdateIssueComment", fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/comments/%s", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.CommentID),
+	), map[string]any{"body": iss.Comment}, nil)
+
+	return formatErr(err, resp, "update comment of issue")
+}
+
+func (c *ClientTarget) AddIssueAssignees(iss *IssueParameter) error {
+	v, err := c.GetIssue(iss)
+	if err != nil {
+		return err
+	}
+
+	current := sets.NewString(v.Assignees...)
+	assignees := v.Assignees
+	for _, u := range iss.Reviewers {
+		if !current.Has(u) {
+			current.Insert(u)
+			assignees = append(assignees, u)
 		}
 	}
 
-	_, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsCommentsId(
-		context.Background(), iss.Org, iss.Repo, id, nil)
-	return formatErr(err, "delete comment of pr")
+	if len(assignees) == len(v.Assignees) {
+		return nil
+	}
+
+	return c.setIssueAssignees("AddIssueAssignees", iss, assignees)
+}
+
+func (c *ClientTarget) RemoveIssueAssignees(iss *IssueParameter) error {
+	v, err := c.GetIssue(iss)
+	if err != nil {
+		return err
+	}
+
+	removed := sets.NewString(iss.Reviewers...)
+	var assignees []string
+	for _, u := range v.Assignees {
+		if !removed.Has(u) {
+			assignees = append(assignees, u)
+		}
+	}
+
+	if len(assignees) == len(v.Assignees) {
+		return nil
+	}
+
+	return c.setIssueAssignees("RemoveIssueAssignees", iss, assignees)
+}
+
+// setIssueAssignees makes the first one the assignee and the others the collaborators,
+// it clears them if assignees is empty which the generated client can't do.
+func (c *ClientTarget) setIssueAssignees(op string, iss *IssueParameter, assignees []string) error {
+	body := map[string]any{"assignee": "", "collaborators": ""}
+	if len(assignees) > 0 {
+		body["assignee"] = assignees[0]
+		body["collaborators"] = strings.Join(assignees[1:], ",")
+	}
+
+	return c.updateIssue(op, iss, body, "update assignees of issue")
+}
+
+func (c *ClientTarget) CloseIssue(iss *IssueParameter) error {
+	return c.updateIssue("CloseIssue", iss, map[string]any{"state": IssueStateClosed}, "close issue")
+}
+
+func (c *ClientTarget) ReopenIssue(iss *IssueParameter) error {
+	return c.updateIssue("ReopenIssue", iss, map[string]any{"state": IssueStateOpen}, "reopen issue")
+}
+
+func (c *ClientTarget) SetIssueMilestone(iss *IssueParameter) error {
+	var milestone any
+	if iss.Milestone != "" {
+		n, err := strconv.Atoi(iss.Milestone)
+		if err != nil {
+			return fmt.Errorf("invalid milestone %s", iss.Milestone)
+		}
+
+		milestone = n
+	}
+
+	return c.updateIssue("SetIssueMilestone", iss, map[string]any{"milestone": milestone}, "set milestone of issue")
+}
+
+func (c *ClientTarget) SetIssueType(iss *IssueParameter) error {
+	return c.updateIssue("SetIssueType", iss, map[string]any{"issue_type": iss.IssueType}, "set type of issue")
+}
+
+// updateIssue updates the fields of issue in body.
+func (c *ClientTarget) updateIssue(op string, iss *IssueParameter, body map[string]any, doWhat string) error {
+	body["repo"] = iss.Repo
+
+	resp, err := c.patch(op, fmt.Sprintf(
+		"/v5/repos/%s/issues/%s", url.PathEscape(iss.Org), url.PathEscape(iss.Number),
+	), body, nil)
+
+	return formatErr(err, resp, doWhat)
 }
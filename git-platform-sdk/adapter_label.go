@@ -1,57 +1,38 @@
 package sdkadapter
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"math/rand"
+	"hash/fnv"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/antihax/optional"
 	"github.com/opensourceways/go-gitee/gitee"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
-func formatErr(err error, doWhat string) error {
-	if err == nil {
-		return err
-	}
-
-	var msg []byte
-	var v gitee.GenericSwaggerError
-	if errors.As(err, &v) {
-		msg = v.Body()
-	}
-
-	return fmt.Errorf("failed to %s, err: %s, msg: %q", doWhat, err.Error(), msg)
-}
-
 func (c *ClientTarget) GetPRLabels(pr *PRParameter) (*sets.String, error) {
 	lc := sets.NewString()
 
-	p := int32(1)
+	number, _ := strconv.ParseInt(pr.Number, 10, 32)
 	opt := gitee.GetV5ReposOwnerRepoPullsNumberLabelsOpts{}
-	for {
-		opt.Page = optional.NewInt32(p)
-		number, _ := strconv.ParseInt(pr.Number, 10, 32)
-		ls, _, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPullsNumberLabels(
-			context.Background(), pr.Org, pr.Repo, int32(number), &opt)
-		if err != nil {
-			return nil, formatErr(err, "list labels of pr")
-		}
 
-		j := len(ls)
-		if j == 0 {
-			break
-		}
+	resp, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		opt.Page = optional.NewInt32(page)
+		opt.PerPage = optional.NewInt32(size)
 
-		for i := 0; i < j; i++ {
+		ls, resp, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPullsNumberLabels(
+			c.opContext("GetPRLabels"), pr.Org, pr.Repo, int32(number), &opt)
+		for i := range ls {
 			lc.Insert(ls[i].Name)
 		}
 
-		p++
+		return len(ls), resp, err
+	})
+	if err != nil {
+		return nil, formatErr(err, resp, "list labels of pr")
 	}
 
 	return &lc, nil
@@ -60,9 +41,9 @@ func (c *ClientTarget) GetPRLabels(pr *PRParameter) (*sets.String, error) {
 func (c *ClientTarget) AddPRLabels(pr *PRParameter) error {
 	opt := gitee.PullRequestLabelPostParam{Body: pr.Labels}
 	number, _ := strconv.ParseInt(pr.Number, 10, 32)
-	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberLabels(
-		context.Background(), pr.Org, pr.Repo, int32(number), opt)
-	return formatErr(err, "add multi label for pr")
+	_, resp, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberLabels(
+		c.opContext("AddPRLabels"), pr.Org, pr.Repo, int32(number), opt)
+	return formatErr(err, resp, "add multi label for pr")
 }
 
 func (c *ClientTarget) DeletePRLabels(pr *PRParameter) error {
@@ -74,47 +55,172 @@ func (c *ClientTarget) DeletePRLabels(pr *PRParameter) error {
 	label := strings.Replace(strings.Join(pr.Labels, ","), "/", "%2F", -1)
 
 	number, _ := strconv.ParseInt(pr.Number, 10, 32)
-	v, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsLabel(
-		context.Background(), pr.Org, pr.Repo, int32(number), label, nil)
+	resp, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsLabel(
+		c.opContext("DeletePRLabels"), pr.Org, pr.Repo, int32(number), label, nil)
 
-	if err == nil || (v != nil && v.StatusCode == 404) {
+	if err = formatErr(err, resp, "remove label of pr"); IsNotFound(err) {
 		return nil
 	}
-	return formatErr(err, "remove label of pr")
+	return err
 }
 
 func (c *ClientTarget) GetRepoLabels(lp *LabelParameter) (*sets.String, error) {
-	lc := sets.NewString()
+	if lc, ok := c.labels.get(lp.Org, lp.Repo); ok {
+		return &lc, nil
+	}
 
-	ls, _, err := c.ac.LabelsApi.GetV5ReposOwnerRepoLabels(context.Background(), lp.Org, lp.Repo, nil)
-	if j := len(ls); j != 0 {
-		for i := 0; i < j; i++ {
-			lc.Insert(ls[i].Name)
-		}
+	lc, err := c.listLabels(
+		"GetRepoLabels", fmt.Sprintf("/v5/repos/%s/%s/labels", url.PathEscape(lp.Org), url.PathEscape(lp.Repo)),
+	)
+	if err != nil {
+		return &lc, formatErr(err, nil, "get repo labels")
 	}
 
-	return &lc, formatErr(err, "get repo labels")
+	c.labels.set(lp.Org, lp.Repo, lc)
+
+	return &lc, nil
 }
 
 func (c *ClientTarget) AddRepoLabels(lp *LabelParameter) error {
-	if lp.Color == "" {
-		v := rand.New(rand.NewSource(time.Now().Unix()))
-		lp.Color = fmt.Sprintf("%02x%02x%02x", v.Intn(255), v.Intn(255), v.Intn(255))
-	}
 	param := gitee.LabelPostParam{
 		Name:  lp.Name,
 		Color: lp.Color,
 	}
+	if param.Color == "" {
+		param.Color = labelColor(lp.Name)
+	}
+
+	_, resp, err := c.ac.LabelsApi.PostV5ReposOwnerRepoLabels(c.opContext("AddRepoLabels"), lp.Org, lp.Repo, param)
+
+	// the label may be created even if it fails, so invalidate anyway
+	c.labels.invalidate(lp.Org, lp.Repo)
+
+	return formatErr(err, resp, "create a repo label")
+}
+
+func (c *ClientTarget) ListRepoLabels(lp *LabelParameter) ([]Label, error) {
+	ls, err := c.listLabelDetails(
+		"ListRepoLabels", fmt.Sprintf("/v5/repos/%s/%s/labels", url.PathEscape(lp.Org), url.PathEscape(lp.Repo)),
+	)
+
+	return ls, formatErr(err, nil, "list repo labels")
+}
+
+// UpdateRepoLabel updates the color of label, and renames it to lp.NewName if it is set.
+func (c *ClientTarget) UpdateRepoLabel(lp *LabelParameter) error {
+	param := gitee.LabelPatchParam{
+		Name:  lp.NewName,
+		Color: lp.Color,
+	}
+	if param.Name == "" {
+		param.Name = lp.Name
+	}
+
+	// gitee's bug, it can't deal with the label which includes '/'
+	label := strings.Replace(lp.Name, "/", "%2F", -1)
+
+	_, resp, err := c.ac.LabelsApi.PatchV5ReposOwnerRepoLabelsOriginalName(
+		c.opContext("UpdateRepoLabel"), lp.Org, lp.Repo, label, param)
+
+	c.labels.invalidate(lp.Org, lp.Repo)
+
+	return formatErr(err, resp, "update a repo label")
+}
+
+// DeleteRepoLabel deletes the label of repo, it is fine if the label doesn't exist.
+func (c *ClientTarget) DeleteRepoLabel(lp *LabelParameter) error {
+	// gitee's bug, it can't deal with the label which includes '/'
+	label := strings.Replace(lp.Name, "/", "%2F", -1)
+
+	resp, err := c.ac.LabelsApi.DeleteV5ReposOwnerRepoLabelsName(
+		c.opContext("DeleteRepoLabel"), lp.Org, lp.Repo, label, nil)
+
+	c.labels.invalidate(lp.Org, lp.Repo)
+
+	if err = formatErr(err, resp, "delete a repo label"); IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *ClientTarget) ListOrgLabels(org string) ([]Label, error) {
+	ls, err := c.listLabelDetails("ListOrgLabels", fmt.Sprintf("/v5/enterprises/%s/labels", url.PathEscape(org)))
+
+	return ls, formatErr(err, nil, "list enterprise labels")
+}
+
+// AddOrgLabel is not supported, since gitee can't create the enterprise labels by api.
+func (c *ClientTarget) AddOrgLabel(lp *LabelParameter) error {
+	return &Error{Operation: "create an enterprise label", Err: ErrNotSupported}
+}
 
-	_, _, err := c.ac.LabelsApi.PostV5ReposOwnerRepoLabels(context.Background(), lp.Org, lp.Repo, param)
+// labelColor returns the color of label which has no color specified,
+// it is derived from the name so that the label has the same color in all repos.
+func labelColor(name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
 
-	return formatErr(err, "create a repo label")
+	return fmt.Sprintf("%06x", h.Sum32()&0xffffff)
+}
+
+func (c *ClientTarget) GetIssueLabels(iss *IssueParameter) (*sets.String, error) {
+	lc, err := c.listLabels("GetIssueLabels", fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/%s/labels", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.Number),
+	))
+
+	return &lc, formatErr(err, nil, "list labels of issue")
+}
+
+// listLabels lists the names of all the labels by the api of path.
+func (c *ClientTarget) listLabels(op, path string) (sets.String, error) {
+	lc := sets.NewString()
+
+	ls, err := c.listLabelDetails(op, path)
+	for i := range ls {
+		lc.Insert(ls[i].Name)
+	}
+
+	return lc, err
+}
+
+// listLabelDetails lists all the labels by the api of path, since the generated client can't page through them.
+func (c *ClientTarget) listLabelDetails(op, path string) ([]Label, error) {
+	var r []Label
+
+	_, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		var ls []Label
+		resp, err := c.getPage(op, path, page, size, &ls)
+		r = append(r, ls...)
+
+		return len(ls), resp, err
+	})
+
+	return r, err
+}
+
+func (c *ClientTarget) DeleteIssueLabels(iss *IssueParameter) error {
+	if len(iss.Labels) == 0 {
+		return fmt.Errorf("can not found label to remove")
+	}
+
+	for _, l := range iss.Labels {
+		// gitee's bug, it can't deal with the label which includes '/'
+		label := strings.Replace(l, "/", "%2F", -1)
+
+		resp, err := c.ac.LabelsApi.DeleteV5ReposOwnerRepoIssuesNumberLabelsName(
+			c.opContext("DeleteIssueLabels"), iss.Org, iss.Repo, iss.Number, label, nil)
+		if err = formatErr(err, resp, "remove label of issue"); err != nil && !IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (c *ClientTarget) AddIssueLabels(iss *IssueParameter) error {
-	opt := gitee.PullRequestLabelPostParam{Body: iss.Labels}
-	number, _ := strconv.ParseInt(iss.Number, 10, 32)
-	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberLabels(
-		context.Background(), iss.Org, iss.Repo, int32(number), opt)
-	return formatErr(err, "add multi label for pr")
+	resp, err := c.call("AddIssueLabels", http.MethodPost, fmt.Sprintf(
+		"/v5/repos/%s/%s/issues/%s/labels", url.PathEscape(iss.Org), url.PathEscape(iss.Repo), url.PathEscape(iss.Number),
+	), iss.Labels, nil)
+
+	return formatErr(err, resp, "add labels of issue")
 }
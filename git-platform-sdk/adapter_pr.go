@@ -1,9 +1,13 @@
 package sdkadapter
 
 import (
-	"context"
+	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/antihax/optional"
 	"github.com/opensourceways/go-gitee/gitee"
 )
 
@@ -16,23 +20,116 @@ func (c *ClientTarget) DeletePRComment(pr *PRParameter) error {
 		}
 	}
 
-	_, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsCommentsId(
-		context.Background(), pr.Org, pr.Repo, id, nil)
-	return formatErr(err, "delete comment of pr")
+	resp, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsCommentsId(
+		c.opContext("DeletePRComment"), pr.Org, pr.Repo, id, nil)
+	return formatErr(err, resp, "delete comment of pr")
 }
 
 func (c *ClientTarget) AddPRComment(pr *PRParameter) error {
 	opt := gitee.PullRequestCommentPostParam{Body: pr.Comment}
 	number, _ := strconv.ParseInt(pr.Number, 10, 32)
-	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
-		context.Background(), pr.Org, pr.Repo, int32(number), opt)
-	return formatErr(err, "create comment of pr")
+	_, resp, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
+		c.opContext("AddPRComment"), pr.Org, pr.Repo, int32(number), opt)
+	return formatErr(err, resp, "create comment of pr")
 }
 
 func (c *ClientTarget) AssignPR(pr *PRParameter) error {
-	opt := gitee.PullRequestCommentPostParam{Body: pr.Comment}
+	opt := gitee.PullRequestAssigneePostParam{Assignees: strings.Join(pr.Reviewers, ",")}
 	number, _ := strconv.ParseInt(pr.Number, 10, 32)
-	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
-		context.Background(), pr.Org, pr.Repo, int32(number), opt)
-	return formatErr(err, "create comment of pr")
+	_, resp, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberAssignees(
+		c.opContext("AssignPR"), pr.Org, pr.Repo, int32(number), opt)
+	return formatErr(err, resp, "assign pr")
+}
+
+func (c *ClientTarget) ListOpenPRs(org, repo string) ([]string, error) {
+	var r []string
+
+	opt := gitee.GetV5ReposOwnerRepoPullsOpts{State: optional.NewString("open")}
+	resp, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		opt.Page = optional.NewInt32(page)
+		opt.PerPage = optional.NewInt32(size)
+
+		prs, resp, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPulls(c.opContext("ListOpenPRs"), org, repo, &opt)
+		for i := range prs {
+			r = append(r, strconv.Itoa(int(prs[i].Number)))
+		}
+
+		return len(prs), resp, err
+	})
+	if err != nil {
+		return nil, formatErr(err, resp, "list open prs")
+	}
+
+	return r, nil
+}
+
+func (c *ClientTarget) GetPR(pr *PRParameter) (*PullRequest, error) {
+	var v giteePullRequest
+	resp, err := c.get("GetPR", prPath(pr, ""), &v)
+	if err != nil {
+		return nil, formatErr(err, resp, "get pr")
+	}
+
+	return v.toPullRequest(), nil
+}
+
+// GetPRChangedFiles lists the files changed by the PR. Gitee responds all of them at once.
+func (c *ClientTarget) GetPRChangedFiles(pr *PRParameter) ([]PRFile, error) {
+	var fs []giteePRFile
+	resp, err := c.get("GetPRChangedFiles", prPath(pr, "/files"), &fs)
+	if err != nil {
+		return nil, formatErr(err, resp, "list changed files of pr")
+	}
+
+	r := make([]PRFile, len(fs))
+	for i := range fs {
+		r[i] = fs[i].toPRFile()
+	}
+
+	return r, nil
+}
+
+// ListPRCommits lists the commits of PR. Gitee responds all of them at once, at most 250 commits.
+func (c *ClientTarget) ListPRCommits(pr *PRParameter) ([]Commit, error) {
+	var cs []giteeCommit
+	resp, err := c.get("ListPRCommits", prPath(pr, "/commits"), &cs)
+	if err != nil {
+		return nil, formatErr(err, resp, "list commits of pr")
+	}
+
+	r := make([]Commit, len(cs))
+	for i := range cs {
+		r[i] = cs[i].toCommit()
+	}
+
+	return r, nil
+}
+
+func (c *ClientTarget) ListPRComments(pr *PRParameter) ([]Comment, error) {
+	r, err := c.listComments("ListPRComments", prPath(pr, "/comments"))
+
+	return r, formatErr(err, nil, "list comments of pr")
+}
+
+// listComments lists all the comments by the api of path page by page.
+func (c *ClientTarget) listComments(op, path string) ([]Comment, error) {
+	var r []Comment
+
+	_, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		var cs []giteeComment
+		resp, err := c.getPage(op, path, page, size, &cs)
+		for i := range cs {
+			r = append(r, cs[i].toComment())
+		}
+
+		return len(cs), resp, err
+	})
+
+	return r, err
+}
+
+func prPath(pr *PRParameter, sub string) string {
+	return fmt.Sprintf(
+		"/v5/repos/%s/%s/pulls/%s%s", url.PathEscape(pr.Org), url.PathEscape(pr.Repo), url.PathEscape(pr.Number), sub,
+	)
 }
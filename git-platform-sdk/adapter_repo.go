@@ -9,3 +9,8 @@ func (c *ClientTarget) ListCollaborator(org, repo string) ([]string, error) {
 
 	return nil, nil
 }
+
+func (c *ClientTarget) Ping() error {
+	_, resp, err := c.ac.UsersApi.GetV5User(c.opContext("Ping"), nil)
+	return formatErr(err, resp, "get the authenticated user")
+}
@@ -0,0 +1,199 @@
+package sdkadapter
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// maxCachedResponses is the maximum number of GET responses kept for conditional requests
+	maxCachedResponses = 2000
+
+	// repoLabelsTTL is how long the labels of a repo are cached, since they may be changed by others
+	repoLabelsTTL = 10 * time.Minute
+)
+
+type cachedResponse struct {
+	key    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// etagTransport caches the responses of GET requests which have ETag, and sends the
+// later requests with If-None-Match. The cached response is served if the platform
+// responds 304 which doesn't consume the quota of api. The least recently used response
+// is evicted when there are limit ones.
+type etagTransport struct {
+	base  http.RoundTripper
+	limit int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru is the cached responses from the most recently used one
+	lru *list.List
+}
+
+func newETagTransport(base http.RoundTripper) *etagTransport {
+	return &etagTransport{
+		base:    base,
+		limit:   maxCachedResponses,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry := t.get(key)
+
+	r := req
+	if entry != nil && req.Header.Get("If-None-Match") == "" {
+		r = req.Clone(req.Context())
+		r.Header.Set("If-None-Match", entry.etag)
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil && r != req:
+		discard(resp)
+
+		return entry.response(req), nil
+
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		t.put(&cachedResponse{
+			key:    key,
+			etag:   resp.Header.Get("ETag"),
+			header: resp.Header.Clone(),
+			body:   body,
+		})
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
+}
+
+func (t *etagTransport) get(key string) *cachedResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+
+	t.lru.MoveToFront(e)
+
+	return e.Value.(*cachedResponse)
+}
+
+func (t *etagTransport) put(v *cachedResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e, ok := t.entries[v.key]; ok {
+		e.Value = v
+		t.lru.MoveToFront(e)
+
+		return
+	}
+
+	if t.lru.Len() >= t.limit {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.entries, oldest.Value.(*cachedResponse).key)
+	}
+
+	t.entries[v.key] = t.lru.PushFront(v)
+}
+
+func (v *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        v.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(v.body)),
+		ContentLength: int64(len(v.body)),
+		Request:       req,
+	}
+}
+
+type repoLabels struct {
+	labels  sets.String
+	expires time.Time
+}
+
+// repoLabelCache caches the labels of repos, it is invalidated by the writes of the client.
+type repoLabelCache struct {
+	mu    sync.Mutex
+	repos map[string]repoLabels
+	now   func() time.Time
+}
+
+func newRepoLabelCache() *repoLabelCache {
+	return &repoLabelCache{repos: make(map[string]repoLabels), now: time.Now}
+}
+
+func (c *repoLabelCache) get(org, repo string) (sets.String, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.repos[org+"/"+repo]
+	if !ok || c.now().After(v.expires) {
+		return nil, false
+	}
+
+	return sets.NewString(v.labels.UnsortedList()...), true
+}
+
+func (c *repoLabelCache) set(org, repo string, labels sets.String) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.repos[org+"/"+repo] = repoLabels{
+		labels:  sets.NewString(labels.UnsortedList()...),
+		expires: c.now().Add(repoLabelsTTL),
+	}
+}
+
+func (c *repoLabelCache) invalidate(org, repo string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.repos, org+"/"+repo)
+	c.mu.Unlock()
+}
@@ -0,0 +1,124 @@
+package sdkadapter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestETagTransportNotModified(t *testing.T) {
+	var calls int
+	tr := newETagTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+
+		rec := httptest.NewRecorder()
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			rec.WriteHeader(http.StatusNotModified)
+		} else {
+			rec.Header().Set("ETag", `"v1"`)
+			_, _ = rec.WriteString(`[{"name":"bug"}]`)
+		}
+
+		return rec.Result(), nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		resp, err := tr.RoundTrip(httptest.NewRequest(http.MethodGet, "https://gitee.com/api/v5/repos/o/r/labels", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != `[{"name":"bug"}]` {
+			t.Errorf("request %d: expected the cached body, got %d %s", i, resp.StatusCode, body)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestETagTransportEviction(t *testing.T) {
+	tr := newETagTransport(nil)
+	tr.limit = 2
+
+	tr.put(&cachedResponse{key: "a"})
+	tr.put(&cachedResponse{key: "b"})
+	tr.get("a")
+	tr.put(&cachedResponse{key: "c"})
+
+	if tr.get("b") != nil {
+		t.Errorf("Expected the least recently used one to be evicted")
+	}
+
+	if tr.get("a") == nil || tr.get("c") == nil {
+		t.Errorf("Expected the recently used ones to be kept")
+	}
+}
+
+func TestRepoLabelCacheInvalidation(t *testing.T) {
+	var gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/labels") {
+			atomic.AddInt32(&gets, 1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":"bug"}]`))
+	}))
+	defer srv.Close()
+
+	c := NewClientTarget(srv.Client(), srv.URL)
+	lp := &LabelParameter{Org: "o", Repo: "r", Name: "kind/feature"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetRepoLabels(lp); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&gets); n != 1 {
+		t.Fatalf("Expected the labels to be cached, got %d requests", n)
+	}
+
+	_ = c.AddRepoLabels(lp)
+
+	if _, err := c.GetRepoLabels(lp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&gets); n != 2 {
+		t.Errorf("Expected the labels to be fetched after the write, got %d requests", n)
+	}
+}
+
+func TestRepoLabelCacheTTL(t *testing.T) {
+	now := time.Now()
+	c := newRepoLabelCache()
+	c.now = func() time.Time { return now }
+
+	c.set("o", "r", sets.NewString("bug"))
+
+	now = now.Add(repoLabelsTTL - time.Second)
+	if _, ok := c.get("o", "r"); !ok {
+		t.Errorf("Expected the labels to be cached before the ttl")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.get("o", "r"); ok {
+		t.Errorf("Expected the labels to expire after the ttl")
+	}
+}
@@ -0,0 +1,164 @@
+package sdkadapter
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"community-robot-lib/httprecord"
+)
+
+// newReplayClient creates a client which responds by the recorded fixture.
+func newReplayClient(t *testing.T, fixture string) *ClientTarget {
+	t.Helper()
+
+	rp, err := httprecord.NewReplayer(fixture, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := rp.Err(); err != nil {
+			t.Error(err)
+		}
+
+		if v := rp.Unused(); len(v) != 0 {
+			t.Errorf("Expected all the fixtures are used, unused: %v", v)
+		}
+	})
+
+	return NewClientTarget(&http.Client{Transport: rp}, "https://gitee.com/api")
+}
+
+func TestLabelsContract(t *testing.T) {
+	c := newReplayClient(t, "testdata/labels.jsonl")
+
+	labels, err := c.GetRepoLabels(&LabelParameter{Org: "openeuler", Repo: "kernel"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v := labels.List(); len(v) != 3 || !labels.Has("newcomer") {
+		t.Errorf("Expected the labels of all pages, got %v", v)
+	}
+
+	labels, err = c.GetIssueLabels(&IssueParameter{Org: "openeuler", Repo: "kernel", Number: "I8ABCD"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v := labels.List(); len(v) != 1 || v[0] != "sig/Kernel" {
+		t.Errorf("Unexpected labels of issue: %v", v)
+	}
+
+	if _, err = c.GetIssueLabels(&IssueParameter{Org: "openeuler", Repo: "kernel", Number: "I8NONE"}); err == nil {
+		t.Errorf("Expected error for the issue which doesn't exist")
+	}
+}
+
+func TestPRContract(t *testing.T) {
+	c := newReplayClient(t, "testdata/pr.jsonl")
+	pr := &PRParameter{Org: "openeuler", Repo: "kernel", Number: "12"}
+
+	v, err := c.GetPR(pr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v.Number != "12" || v.Author != "alice" || !v.Draft || v.Base != "master" || v.HeadRepo != "alice/kernel" {
+		t.Errorf("Unexpected pr: %+v", v)
+	}
+
+	files, err := c.GetPRChangedFiles(pr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expect := []PRFile{
+		{Filename: "mm/dma.c", Status: FileStatusModified, Additions: 3, Deletions: 1},
+		{Filename: "mm/dma_pool.c", PreviousFilename: "mm/pool.c", Status: FileStatusRenamed},
+		{Filename: "docs/dma.md", Status: FileStatusAdded, Additions: 10},
+	}
+	if !reflect.DeepEqual(files, expect) {
+		t.Errorf("Expected files %+v, got %+v", expect, files)
+	}
+
+	commits, err := c.ListPRCommits(pr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(commits) != 2 || commits[0].Author != "alice" || commits[1].Author != "" || commits[1].AuthorEmail != "alice@localhost" {
+		t.Errorf("Unexpected commits: %+v", commits)
+	}
+
+	comments, err := c.ListPRComments(pr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(comments) != 101 || comments[100].Author != "carol" || comments[100].ID != "200" {
+		t.Errorf("Expected the comments of all pages, got %d", len(comments))
+	}
+
+	if _, err = c.GetPR(&PRParameter{Org: "openeuler", Repo: "kernel", Number: "99"}); !IsNotFound(err) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestIssueContract(t *testing.T) {
+	c := newReplayClient(t, "testdata/issue.jsonl")
+	iss := &IssueParameter{Org: "openeuler", Repo: "kernel", Number: "I8ABCD"}
+
+	v, err := c.GetIssue(iss)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(v.Assignees, []string{"bob", "carol"}) || v.Milestone != "7" || v.IssueType != "缺陷" {
+		t.Errorf("Unexpected issue: %+v", v)
+	}
+
+	// the collaborator becomes the assignee if the assignee is removed
+	iss.Reviewers = []string{"bob"}
+	if err = c.RemoveIssueAssignees(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err = c.CloseIssue(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err = c.SetIssueMilestone(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	iss.CommentID, iss.Comment = "301", "updated"
+	if err = c.UpdateIssueComment(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	comments, err := c.ListIssueComments(iss)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(comments) != 1 || comments[0].Body != "updated" || comments[0].Author != "robot" {
+		t.Errorf("Unexpected comments: %+v", comments)
+	}
+
+	iss.Comment = "welcome"
+	if err = c.AddIssueComment(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	iss.CommentID = "302"
+	if err = c.DeleteIssueComment(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	iss.Labels = []string{"sig/Kernel", "newcomer"}
+	if err = c.AddIssueLabels(iss); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
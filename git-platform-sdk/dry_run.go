@@ -0,0 +1,142 @@
+package sdkadapter
+
+import "context"
+
+// Client is the platform client used by the robots.
+type Client interface {
+	LabelClient
+	PRClient
+	IssueClient
+	RepoClient
+	StatusClient
+
+	// WithContext returns a client whose calls are made with ctx.
+	WithContext(ctx context.Context) Client
+}
+
+var _ Client = (*ClientTarget)(nil)
+
+// ActionRecorder records a write call which is intercepted in dry run mode.
+// The action is the name of method and the payload is its parameter.
+type ActionRecorder func(action string, payload any)
+
+// NewDryRunClient returns a Client which passes the read calls to c and
+// hands all the write calls to record instead of sending them to the platform.
+func NewDryRunClient(c Client, record ActionRecorder) Client {
+	return &dryRunClient{Client: c, record: record}
+}
+
+type dryRunClient struct {
+	Client
+
+	record ActionRecorder
+}
+
+func (c *dryRunClient) WithContext(ctx context.Context) Client {
+	return &dryRunClient{Client: c.Client.WithContext(ctx), record: c.record}
+}
+
+func (c *dryRunClient) AddRepoLabels(lp *LabelParameter) error {
+	c.record("AddRepoLabels", lp)
+	return nil
+}
+
+func (c *dryRunClient) UpdateRepoLabel(lp *LabelParameter) error {
+	c.record("UpdateRepoLabel", lp)
+	return nil
+}
+
+func (c *dryRunClient) DeleteRepoLabel(lp *LabelParameter) error {
+	c.record("DeleteRepoLabel", lp)
+	return nil
+}
+
+func (c *dryRunClient) AddOrgLabel(lp *LabelParameter) error {
+	c.record("AddOrgLabel", lp)
+	return nil
+}
+
+func (c *dryRunClient) AddPRLabels(pr *PRParameter) error {
+	c.record("AddPRLabels", pr)
+	return nil
+}
+
+func (c *dryRunClient) DeletePRLabels(pr *PRParameter) error {
+	c.record("DeletePRLabels", pr)
+	return nil
+}
+
+func (c *dryRunClient) AddIssueLabels(iss *IssueParameter) error {
+	c.record("AddIssueLabels", iss)
+	return nil
+}
+
+func (c *dryRunClient) DeleteIssueLabels(iss *IssueParameter) error {
+	c.record("DeleteIssueLabels", iss)
+	return nil
+}
+
+func (c *dryRunClient) AddPRComment(pr *PRParameter) error {
+	c.record("AddPRComment", pr)
+	return nil
+}
+
+func (c *dryRunClient) DeletePRComment(pr *PRParameter) error {
+	c.record("DeletePRComment", pr)
+	return nil
+}
+
+func (c *dryRunClient) AssignPR(pr *PRParameter) error {
+	c.record("AssignPR", pr)
+	return nil
+}
+
+func (c *dryRunClient) AddIssueComment(iss *IssueParameter) error {
+	c.record("AddIssueComment", iss)
+	return nil
+}
+
+func (c *dryRunClient) DeleteIssueComment(iss *IssueParameter) error {
+	c.record("DeleteIssueComment", iss)
+	return nil
+}
+
+func (c *dryRunClient) UpdateIssueComment(iss *IssueParameter) error {
+	c.record("UpdateIssueComment", iss)
+	return nil
+}
+
+func (c *dryRunClient) AddIssueAssignees(iss *IssueParameter) error {
+	c.record("AddIssueAssignees", iss)
+	return nil
+}
+
+func (c *dryRunClient) RemoveIssueAssignees(iss *IssueParameter) error {
+	c.record("RemoveIssueAssignees", iss)
+	return nil
+}
+
+func (c *dryRunClient) CloseIssue(iss *IssueParameter) error {
+	c.record("CloseIssue", iss)
+	return nil
+}
+
+func (c *dryRunClient) ReopenIssue(iss *IssueParameter) error {
+	c.record("ReopenIssue", iss)
+	return nil
+}
+
+func (c *dryRunClient) SetIssueMilestone(iss *IssueParameter) error {
+	c.record("SetIssueMilestone", iss)
+	return nil
+}
+
+func (c *dryRunClient) SetIssueType(iss *IssueParameter) error {
+	c.record("SetIssueType", iss)
+	return nil
+}
+
+func (c *dryRunClient) AssignIssue(iss *IssueParameter) error {
+	c.record("AssignIssue", iss)
+	return nil
+}
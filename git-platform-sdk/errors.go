@@ -0,0 +1,177 @@
+package sdkadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/go-gitee/gitee"
+)
+
+// ErrNotSupported means the platform doesn't support the call.
+var ErrNotSupported = errors.New("not supported by the platform")
+
+// Error is the error of a platform api call.
+type Error struct {
+	// Operation is what the call does, such as "create a repo label"
+	Operation string
+	// StatusCode is the http status of response, it is 0 if there is no response
+	StatusCode int
+	// Message is the message returned by the platform
+	Message string
+	// Retryable means the call may succeed if it is made again later
+	Retryable bool
+	// RetryAfter is how long to wait before retrying, it is set when rate limited
+	RetryAfter time.Duration
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("failed to %s, err: %v", e.Operation, e.Err)
+	}
+
+	return fmt.Sprintf("failed to %s, status: %d, msg: %q", e.Operation, e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err means the resource doesn't exist.
+func IsNotFound(err error) bool {
+	return statusOf(err) == http.StatusNotFound
+}
+
+// IsForbidden reports whether err means the token has no permission to do it.
+func IsForbidden(err error) bool {
+	return statusOf(err) == http.StatusForbidden
+}
+
+// IsAlreadyExists reports whether err means the resource to create exists already.
+func IsAlreadyExists(err error) bool {
+	var v *Error
+	if !errors.As(err, &v) {
+		return false
+	}
+
+	switch v.StatusCode {
+	case http.StatusConflict:
+		return true
+
+	case http.StatusUnprocessableEntity, http.StatusBadRequest:
+		msg := strings.ToLower(v.Message)
+
+		return strings.Contains(msg, "already") || strings.Contains(msg, "exist") || strings.Contains(msg, "已存在")
+	}
+
+	return false
+}
+
+// IsRateLimited reports whether err is caused by the rate limit of platform api,
+// and how long to wait before retrying.
+func IsRateLimited(err error) (time.Duration, bool) {
+	var v *Error
+	if errors.As(err, &v) && v.StatusCode == http.StatusTooManyRequests {
+		return v.RetryAfter, true
+	}
+
+	var rl *RateLimitError
+	if errors.As(err, &rl) {
+		return rl.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+// IsRetryable reports whether the call may succeed if it is made again later.
+func IsRetryable(err error) bool {
+	var v *Error
+
+	return errors.As(err, &v) && v.Retryable
+}
+
+func statusOf(err error) int {
+	var v *Error
+	if errors.As(err, &v) {
+		return v.StatusCode
+	}
+
+	return 0
+}
+
+// formatErr converts the error of the call which does doWhat to *Error.
+// resp is the response of the call, it can be nil.
+func formatErr(err error, resp *http.Response, doWhat string) error {
+	if err == nil {
+		return nil
+	}
+
+	var v *Error
+	if errors.As(err, &v) {
+		if v.Operation == "" {
+			v.Operation = doWhat
+		}
+
+		return v
+	}
+
+	e := &Error{Operation: doWhat, Err: err}
+
+	var rl *RateLimitError
+	if errors.As(err, &rl) {
+		e.StatusCode = http.StatusTooManyRequests
+		e.RetryAfter = rl.RetryAfter
+		e.Retryable = true
+
+		return e
+	}
+
+	var se gitee.GenericSwaggerError
+	if errors.As(err, &se) {
+		e.Message = messageOf(se.Body())
+
+		// the error of generated client is the status of response, such as "404 Not Found"
+		if resp == nil {
+			e.StatusCode, _ = strconv.Atoi(strings.SplitN(se.Error(), " ", 2)[0])
+		}
+	}
+
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+	}
+
+	e.Retryable = e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+
+	return e
+}
+
+// newStatusError creates the error of response which has the body and an unexpected status.
+func newStatusError(resp *http.Response, body []byte) *Error {
+	code := resp.StatusCode
+
+	return &Error{
+		StatusCode: code,
+		Message:    messageOf(body),
+		Retryable:  code == http.StatusTooManyRequests || code >= http.StatusInternalServerError,
+		Err:        fmt.Errorf("response has status:%s", resp.Status),
+	}
+}
+
+// messageOf returns the message in the body of error response, which is like {"message": "..."}.
+func messageOf(body []byte) string {
+	var v struct {
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(body, &v); err == nil && v.Message != "" {
+		return v.Message
+	}
+
+	return strings.TrimSpace(string(body))
+}
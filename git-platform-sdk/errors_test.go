@@ -0,0 +1,51 @@
+package sdkadapter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFormatErr(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusNotFound}
+
+	err := formatErr(errors.New("404 Not Found"), resp, "list labels of issue")
+	if !IsNotFound(err) || IsRetryable(err) {
+		t.Errorf("Expected not found and not retryable, got %v", err)
+	}
+
+	// the error wrapped by the caller is still recognized
+	if !IsNotFound(fmt.Errorf("sync labels: %w", err)) {
+		t.Errorf("Expected not found for the wrapped error")
+	}
+
+	err = formatErr(&RateLimitError{RetryAfter: time.Minute}, nil, "assign issue")
+	if d, ok := IsRateLimited(err); !ok || d != time.Minute || !IsRetryable(err) {
+		t.Errorf("Expected rate limited and retryable after 1m, got %v", err)
+	}
+
+	if formatErr(nil, resp, "assign issue") != nil {
+		t.Errorf("Expected nil for nil error")
+	}
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect bool
+	}{
+		{&Error{StatusCode: http.StatusConflict}, true},
+		{&Error{StatusCode: http.StatusUnprocessableEntity, Message: "Name has already been taken"}, true},
+		{&Error{StatusCode: http.StatusBadRequest, Message: "标签已存在"}, true},
+		{&Error{StatusCode: http.StatusUnprocessableEntity, Message: "Name is invalid"}, false},
+		{errors.New("label exists"), false},
+	}
+
+	for i, c := range cases {
+		if v := IsAlreadyExists(c.err); v != c.expect {
+			t.Errorf("case %d: expected %t, got %t", i, c.expect, v)
+		}
+	}
+}
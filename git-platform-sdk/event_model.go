@@ -0,0 +1,257 @@
+package sdkadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrEventKind means the payload is not of the event kind which it is parsed to.
+var ErrEventKind = errors.New("the payload is not of this event kind")
+
+// Number is the number of issue or PR, the payload encodes it as a string or a number.
+type Number string
+
+func (n *Number) UnmarshalJSON(b []byte) error {
+	*n = Number(strings.Trim(string(b), `"`))
+	if *n == "null" {
+		*n = ""
+	}
+
+	return nil
+}
+
+func (n Number) String() string {
+	return string(n)
+}
+
+type EventUser struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Type is "Bot" for the bot accounts
+	Type string `json:"type"`
+}
+
+// IsBot reports whether the user is a bot account, which is marked by its type or the suffix "[bot]" of login.
+func (u *EventUser) IsBot() bool {
+	return strings.EqualFold(u.Type, "bot") || strings.HasSuffix(u.Login, "[bot]")
+}
+
+type EventRepo struct {
+	FullName      string    `json:"full_name"`
+	Name          string    `json:"name"`
+	HtmlURL       string    `json:"html_url"`
+	DefaultBranch string    `json:"default_branch"`
+	Private       bool      `json:"private"`
+	Owner         EventUser `json:"owner"`
+}
+
+// OrgAndRepo splits the full name of repo.
+func (r *EventRepo) OrgAndRepo() (string, string) {
+	if v := strings.Split(r.FullName, "/"); len(v) == 2 {
+		return v[0], v[1]
+	}
+
+	return r.Owner.Login, r.Name
+}
+
+// EventBranch is the base or head branch of PR.
+type EventBranch struct {
+	Ref  string     `json:"ref"`
+	Sha  string     `json:"sha"`
+	Repo *EventRepo `json:"repo"`
+}
+
+type EventPR struct {
+	Number    Number      `json:"number"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	State     string      `json:"state"`
+	HtmlURL   string      `json:"html_url"`
+	Draft     bool        `json:"draft"`
+	Merged    bool        `json:"merged"`
+	User      EventUser   `json:"user"`
+	Head      EventBranch `json:"head"`
+	Base      EventBranch `json:"base"`
+	Labels    []Label     `json:"labels"`
+	Assignees []EventUser `json:"assignees"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type EventIssue struct {
+	Number    Number     `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	HtmlURL   string     `json:"html_url"`
+	IssueType string     `json:"issue_type"`
+	User      EventUser  `json:"user"`
+	Assignee  *EventUser `json:"assignee"`
+	Labels    []Label    `json:"labels"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type EventComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	HtmlURL   string    `json:"html_url"`
+	User      EventUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type EventReview struct {
+	ID          int64     `json:"id"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	User        EventUser `json:"user"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type EventCommit struct {
+	ID        string    `json:"id"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    EventUser `json:"author"`
+	Added     []string  `json:"added"`
+	Removed   []string  `json:"removed"`
+	Modified  []string  `json:"modified"`
+}
+
+type IssueEvent struct {
+	Action     string     `json:"action"`
+	Repository EventRepo  `json:"repository"`
+	Issue      EventIssue `json:"issue"`
+	Sender     EventUser  `json:"sender"`
+}
+
+type PullRequestEvent struct {
+	Action      string    `json:"action"`
+	Repository  EventRepo `json:"repository"`
+	PullRequest EventPR   `json:"pull_request"`
+	Sender      EventUser `json:"sender"`
+}
+
+type PushEvent struct {
+	Ref        string        `json:"ref"`
+	Before     string        `json:"before"`
+	After      string        `json:"after"`
+	Created    bool          `json:"created"`
+	Deleted    bool          `json:"deleted"`
+	Commits    []EventCommit `json:"commits"`
+	HeadCommit *EventCommit  `json:"head_commit"`
+	Pusher     EventUser     `json:"pusher"`
+	Repository EventRepo     `json:"repository"`
+	Sender     EventUser     `json:"sender"`
+}
+
+// Branch returns the branch pushed to, it is empty if a tag is pushed.
+func (e *PushEvent) Branch() string {
+	if v := strings.TrimPrefix(e.Ref, "refs/heads/"); v != e.Ref {
+		return v
+	}
+
+	return ""
+}
+
+// CommentEvent is a comment on an issue or a PR, exactly one of Issue and PullRequest is set.
+type CommentEvent struct {
+	Action      string       `json:"action"`
+	Repository  EventRepo    `json:"repository"`
+	Comment     EventComment `json:"comment"`
+	Issue       *EventIssue  `json:"issue"`
+	PullRequest *EventPR     `json:"pull_request"`
+	Sender      EventUser    `json:"sender"`
+}
+
+// IsPR reports whether the comment is on a PR.
+func (e *CommentEvent) IsPR() bool {
+	return e.PullRequest != nil
+}
+
+type ReviewEvent struct {
+	Action      string      `json:"action"`
+	Repository  EventRepo   `json:"repository"`
+	Review      EventReview `json:"review"`
+	PullRequest EventPR     `json:"pull_request"`
+	Sender      EventUser   `json:"sender"`
+}
+
+// eventKind tells which objects the payload has.
+type eventKind struct {
+	Ref         *string          `json:"ref"`
+	Issue       *json.RawMessage `json:"issue"`
+	PullRequest *json.RawMessage `json:"pull_request"`
+	Comment     *json.RawMessage `json:"comment"`
+	Review      *json.RawMessage `json:"review"`
+}
+
+// AsIssueEvent returns the typed form of issue event, the payload is parsed only once.
+func (ge *GenericEvent) AsIssueEvent() (*IssueEvent, error) {
+	return parseTyped[IssueEvent](ge, func(k *eventKind) bool {
+		return k.Issue != nil && k.Comment == nil
+	})
+}
+
+// AsPullRequestEvent returns the typed form of PR event, the payload is parsed only once.
+func (ge *GenericEvent) AsPullRequestEvent() (*PullRequestEvent, error) {
+	return parseTyped[PullRequestEvent](ge, func(k *eventKind) bool {
+		return k.PullRequest != nil && k.Comment == nil && k.Review == nil
+	})
+}
+
+// AsPushEvent returns the typed form of push event, the payload is parsed only once.
+func (ge *GenericEvent) AsPushEvent() (*PushEvent, error) {
+	return parseTyped[PushEvent](ge, func(k *eventKind) bool {
+		return k.Ref != nil && k.Issue == nil && k.PullRequest == nil
+	})
+}
+
+// AsCommentEvent returns the typed form of comment event, the payload is parsed only once.
+func (ge *GenericEvent) AsCommentEvent() (*CommentEvent, error) {
+	return parseTyped[CommentEvent](ge, func(k *eventKind) bool {
+		return k.Comment != nil && (k.Issue != nil || k.PullRequest != nil)
+	})
+}
+
+// AsReviewEvent returns the typed form of review event, the payload is parsed only once.
+func (ge *GenericEvent) AsReviewEvent() (*ReviewEvent, error) {
+	return parseTyped[ReviewEvent](ge, func(k *eventKind) bool {
+		return k.Review != nil && k.PullRequest != nil
+	})
+}
+
+// parseTyped parses the payload to T if it is of the kind, and caches the result in event.
+// The cached one is shared by all the callers, so it should not be modified.
+func parseTyped[T any](ge *GenericEvent, isKind func(*eventKind) bool) (*T, error) {
+	if v, ok := ge.typed.(*T); ok {
+		return v, nil
+	}
+
+	if len(ge.Payload) == 0 {
+		return nil, errors.New("the event has no payload")
+	}
+
+	var k eventKind
+	if err := json.Unmarshal(ge.Payload, &k); err != nil {
+		return nil, err
+	}
+
+	v := new(T)
+	if !isKind(&k) {
+		return nil, fmt.Errorf("%w: %T", ErrEventKind, v)
+	}
+
+	if err := json.Unmarshal(ge.Payload, v); err != nil {
+		return nil, err
+	}
+
+	ge.typed = v
+
+	return v, nil
+}
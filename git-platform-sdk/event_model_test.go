@@ -0,0 +1,76 @@
+package sdkadapter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypedEvents(t *testing.T) {
+	comment := &GenericEvent{Payload: []byte(`{
+		"action": "created",
+		"repository": {"full_name": "openeuler/kernel"},
+		"comment": {"id": 301, "body": "/sig kernel", "user": {"login": "bob"}},
+		"issue": {"number": "I8ABCD", "user": {"login": "alice"}, "assignee": null},
+		"pull_request": null
+	}`)}
+
+	c, err := comment.AsCommentEvent()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if org, repo := c.Repository.OrgAndRepo(); org != "openeuler" || repo != "kernel" {
+		t.Errorf("Unexpected repo: %s/%s", org, repo)
+	}
+
+	if c.IsPR() || c.Issue.Number != "I8ABCD" || c.Comment.User.Login != "bob" {
+		t.Errorf("Unexpected comment event: %+v", c)
+	}
+
+	if v, _ := comment.AsCommentEvent(); v != c {
+		t.Errorf("Expected the parsed event to be cached")
+	}
+
+	if _, err = comment.AsIssueEvent(); !errors.Is(err, ErrEventKind) {
+		t.Errorf("Expected the comment not to be an issue event, got %v", err)
+	}
+
+	pr := &GenericEvent{Payload: []byte(`{
+		"action": "open",
+		"pull_request": {"number": 12, "draft": true, "head": {"ref": "fix", "repo": {"full_name": "alice/kernel"}}}
+	}`)}
+
+	p, err := pr.AsPullRequestEvent()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p.PullRequest.Number != "12" || !p.PullRequest.Draft || p.PullRequest.Head.Repo.FullName != "alice/kernel" {
+		t.Errorf("Unexpected pr event: %+v", p)
+	}
+
+	push := &GenericEvent{Payload: []byte(`{"ref": "refs/tags/v1.0", "commits": []}`)}
+
+	v, err := push.AsPushEvent()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v.Branch() != "" {
+		t.Errorf("Expected no branch for the tag, got %s", v.Branch())
+	}
+}
+
+func TestNormalizeAction(t *testing.T) {
+	for action, expect := range map[string]string{"Open": ActionOpened, "update": ActionEdited, "assign": "assign"} {
+		if v := NormalizeAction(action); v != expect {
+			t.Errorf("%s: expected %s, got %s", action, expect, v)
+		}
+	}
+
+	for action, expect := range map[string]bool{ActionMerged: true, "merge": false, "": false} {
+		if v := IsNormalizedAction(action); v != expect {
+			t.Errorf("%q: expected %t, got %t", action, expect, v)
+		}
+	}
+}
@@ -9,6 +9,7 @@ package sdkadapter
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/gob"
@@ -26,6 +27,60 @@ const (
 	ActionStateCreated = "created"
 )
 
+// The normalized actions of issue and PR events.
+const (
+	ActionOpened      = "opened"
+	ActionReopened    = "reopened"
+	ActionEdited      = "edited"
+	ActionClosed      = "closed"
+	ActionMerged      = "merged"
+	ActionTransferred = "transferred"
+)
+
+// actionAliases maps the actions reported by the platforms to the normalized ones.
+// AtomGit reports the past tense, such as "opened", while gitee reports "open" and
+// "update" which is reported when the target branch changes too.
+var actionAliases = map[string]string{
+	"open":        ActionOpened,
+	"opened":      ActionOpened,
+	"create":      ActionOpened,
+	"created":     ActionOpened,
+	"reopen":      ActionReopened,
+	"reopened":    ActionReopened,
+	"edit":        ActionEdited,
+	"edited":      ActionEdited,
+	"update":      ActionEdited,
+	"updated":     ActionEdited,
+	"close":       ActionClosed,
+	"closed":      ActionClosed,
+	"merge":       ActionMerged,
+	"merged":      ActionMerged,
+	"transfer":    ActionTransferred,
+	"transferred": ActionTransferred,
+}
+
+// NormalizeAction returns the normalized action of issue or PR event,
+// the action which is unknown is returned in lower case. It applies to the
+// issue and PR events only, since the comment events report "created" too
+// which would be taken as opened.
+func NormalizeAction(action string) string {
+	action = strings.ToLower(action)
+	if v, ok := actionAliases[action]; ok {
+		return v
+	}
+
+	return action
+}
+
+// IsNormalizedAction reports whether action is one of the normalized actions.
+func IsNormalizedAction(action string) bool {
+	v, ok := actionAliases[action]
+
+	return ok && v == action
+}
+
+// GenericEvent is the flat form of all the events whose fields are set per event kind,
+// AsIssueEvent, AsPullRequestEvent and so on return the typed form parsed from Payload.
 type GenericEvent struct {
 	EventType      int
 	EventName      string
@@ -47,6 +102,25 @@ type GenericEvent struct {
 	IssueComment   string
 	IssueNumber    string
 	Payload        []byte
+
+	// ctx is not encoded, it carries the trace and deadline while the event is handled
+	ctx context.Context
+	// typed is the typed event parsed from Payload, it is not encoded either
+	typed any
+}
+
+// Context returns the context of event, it is never nil.
+func (ge *GenericEvent) Context() context.Context {
+	if ge.ctx != nil {
+		return ge.ctx
+	}
+
+	return context.Background()
+}
+
+// SetContext sets the context which is passed to the platform calls made for the event.
+func (ge *GenericEvent) SetContext(ctx context.Context) {
+	ge.ctx = ctx
 }
 
 func (ge *GenericEvent) ConvertToBytes() ([]byte, error) {
@@ -121,6 +195,8 @@ func (ge *GenericEvent) ConvertFromBytes(b []byte) error {
 		return err
 	}
 
+	ge.typed = nil
+
 	return nil
 }
 
@@ -145,7 +221,26 @@ const (
 	pullRequestCommentEvent
 )
 
+// giteeEvents maps the X-Gitee-Event header of the events forwarded by the gateway to the event type.
+// Gitee reports the comments of issue and PR as "Note Hook", which is taken as the issue comment one here.
+var giteeEvents = map[string]int{
+	"Issue Hook":         issueEvent,
+	"Merge Request Hook": pullRequestEvent,
+	"Push Hook":          pushEvent,
+	"Tag Push Hook":      pushEvent,
+	"Note Hook":          issueCommentEvent,
+}
+
 func GetEventType(header *http.Header) (int, string, error) {
+	if name := header.Get("X-Gitee-Event"); name != "" {
+		t, ok := giteeEvents[name]
+		if !ok {
+			return 0, name, errors.New("unknown X-Gitee-Event header: " + name)
+		}
+
+		return t, name, nil
+	}
+
 	ua := header.Get(WebhookUserAgentKey)
 	if ua == WebhookUserAgentValue {
 		// later
@@ -0,0 +1,846 @@
+// Package fake provides an in-memory git platform which implements the clients of
+// git-platform-sdk, it is used to test robots without calling the real platform.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	sdk "git-platform-sdk"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ErrNotFound is returned when the repo, issue, PR or comment doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// Comment is a comment of issue or PR.
+type Comment struct {
+	ID     string
+	Body   string
+	Author string
+}
+
+// Item is an issue or a PR.
+type Item struct {
+	Number    string
+	Author    string
+	Title     string
+	Body      string
+	Closed    bool
+	Labels    sets.String
+	Assignees sets.String
+	Comments  []Comment
+
+	// the fields below are of issue only
+	Milestone string
+	IssueType string
+
+	// the fields below are of PR only
+	Base    string
+	Head    string
+	Draft   bool
+	Files   []sdk.PRFile
+	Commits []sdk.Commit
+}
+
+// Repo holds the state of a repo.
+type Repo struct {
+	Labels sets.String
+	// LabelDetails are the colors and descriptions of labels, keyed by name
+	LabelDetails  map[string]sdk.Label
+	Collaborators []string
+	Contents      []*sdk.ContentInfo
+
+	prs    map[string]*Item
+	issues map[string]*Item
+}
+
+type fault struct {
+	err   error
+	times int
+}
+
+// Platform is an in-memory git platform. The state should be set up before the robot
+// runs and be checked after the robot finishes, since it is shared without copying.
+type Platform struct {
+	mu        sync.Mutex
+	repos     map[string]*Repo
+	orgLabels map[string][]sdk.Label
+	faults    map[string]*fault
+	calls     []string
+	nextID    int
+}
+
+// New creates an empty platform.
+func New() *Platform {
+	return &Platform{
+		repos:     map[string]*Repo{},
+		orgLabels: map[string][]sdk.Label{},
+		faults:    map[string]*fault{},
+	}
+}
+
+var _ sdk.Client = (*Platform)(nil)
+
+// AddRepo creates a repo with the collaborators, or returns it if it exists.
+func (p *Platform) AddRepo(org, repo string, collaborators ...string) *Repo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k := org + "/" + repo
+	if r, ok := p.repos[k]; ok {
+		return r
+	}
+
+	r := &Repo{
+		Labels:        sets.NewString(),
+		LabelDetails:  map[string]sdk.Label{},
+		Collaborators: collaborators,
+		prs:           map[string]*Item{},
+		issues:        map[string]*Item{},
+	}
+	p.repos[k] = r
+
+	return r
+}
+
+// AddPR creates an open PR of the repo, the repo is created if it doesn't exist.
+func (p *Platform) AddPR(org, repo, number, author string) *Item {
+	r := p.AddRepo(org, repo)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	v := newItem(number, author)
+	r.prs[number] = v
+
+	return v
+}
+
+// AddIssue creates an open issue of the repo, the repo is created if it doesn't exist.
+func (p *Platform) AddIssue(org, repo, number, author string) *Item {
+	r := p.AddRepo(org, repo)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	v := newItem(number, author)
+	r.issues[number] = v
+
+	return v
+}
+
+// Repo returns the repo, it is nil if not exists.
+func (p *Platform) Repo(org, repo string) *Repo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.repos[org+"/"+repo]
+}
+
+// PR returns the PR, it is nil if not exists.
+func (p *Platform) PR(org, repo, number string) *Item {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	v, _ := p.item(org, repo, number, true)
+
+	return v
+}
+
+// Issue returns the issue, it is nil if not exists.
+func (p *Platform) Issue(org, repo, number string) *Item {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	v, _ := p.item(org, repo, number, false)
+
+	return v
+}
+
+// InjectFault makes the next times calls of the operation, which is the method name
+// such as AddPRComment, fail with err. It fails always if times is not positive.
+func (p *Platform) InjectFault(op string, times int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.faults[op] = &fault{err: err, times: times}
+}
+
+// Calls returns the operations called so far in order.
+func (p *Platform) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.calls...)
+}
+
+// WithContext returns the platform itself, the context is ignored.
+func (p *Platform) WithContext(ctx context.Context) sdk.Client {
+	return p
+}
+
+// Ping checks the platform.
+func (p *Platform) Ping() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.enter("Ping")
+}
+
+func (p *Platform) GetRepoLabels(lp *sdk.LabelParameter) (*sets.String, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("GetRepoLabels"); err != nil {
+		return nil, err
+	}
+
+	r, err := p.repo(lp.Org, lp.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyOf(r.Labels), nil
+}
+
+func (p *Platform) AddRepoLabels(lp *sdk.LabelParameter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("AddRepoLabels"); err != nil {
+		return err
+	}
+
+	r, err := p.repo(lp.Org, lp.Repo)
+	if err != nil {
+		return err
+	}
+
+	if r.Labels.Has(lp.Name) {
+		return &sdk.Error{
+			Operation:  "create a repo label",
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("label %s already exists", lp.Name),
+		}
+	}
+
+	r.Labels.Insert(lp.Name)
+	r.LabelDetails[lp.Name] = sdk.Label{Name: lp.Name, Color: lp.Color, Description: lp.Description}
+
+	return nil
+}
+
+func (p *Platform) ListRepoLabels(lp *sdk.LabelParameter) ([]sdk.Label, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("ListRepoLabels"); err != nil {
+		return nil, err
+	}
+
+	r, err := p.repo(lp.Org, lp.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := make([]sdk.Label, 0, r.Labels.Len())
+	for _, name := range r.Labels.List() {
+		l := r.LabelDetails[name]
+		l.Name = name
+		ls = append(ls, l)
+	}
+
+	return ls, nil
+}
+
+func (p *Platform) UpdateRepoLabel(lp *sdk.LabelParameter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("UpdateRepoLabel"); err != nil {
+		return err
+	}
+
+	r, err := p.repo(lp.Org, lp.Repo)
+	if err != nil {
+		return err
+	}
+
+	if !r.Labels.Has(lp.Name) {
+		return notFound(fmt.Sprintf("label %s of %s/%s", lp.Name, lp.Org, lp.Repo))
+	}
+
+	l := r.LabelDetails[lp.Name]
+	l.Name = lp.Name
+	if lp.Color != "" {
+		l.Color = lp.Color
+	}
+	if lp.Description != "" {
+		l.Description = lp.Description
+	}
+
+	if lp.NewName != "" && lp.NewName != lp.Name {
+		r.Labels.Delete(lp.Name)
+		delete(r.LabelDetails, lp.Name)
+		l.Name = lp.NewName
+	}
+
+	r.Labels.Insert(l.Name)
+	r.LabelDetails[l.Name] = l
+
+	return nil
+}
+
+func (p *Platform) DeleteRepoLabel(lp *sdk.LabelParameter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("DeleteRepoLabel"); err != nil {
+		return err
+	}
+
+	r, err := p.repo(lp.Org, lp.Repo)
+	if err != nil {
+		return err
+	}
+
+	r.Labels.Delete(lp.Name)
+	delete(r.LabelDetails, lp.Name)
+
+	return nil
+}
+
+func (p *Platform) ListOrgLabels(org string) ([]sdk.Label, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("ListOrgLabels"); err != nil {
+		return nil, err
+	}
+
+	return append([]sdk.Label(nil), p.orgLabels[org]...), nil
+}
+
+func (p *Platform) AddOrgLabel(lp *sdk.LabelParameter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("AddOrgLabel"); err != nil {
+		return err
+	}
+
+	for _, l := range p.orgLabels[lp.Org] {
+		if l.Name == lp.Name {
+			return &sdk.Error{
+				Operation:  "create an org label",
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("label %s already exists", lp.Name),
+			}
+		}
+	}
+
+	p.orgLabels[lp.Org] = append(
+		p.orgLabels[lp.Org], sdk.Label{Name: lp.Name, Color: lp.Color, Description: lp.Description},
+	)
+
+	return nil
+}
+
+func (p *Platform) GetPRLabels(pr *sdk.PRParameter) (*sets.String, error) {
+	return p.getLabels("GetPRLabels", pr.Org, pr.Repo, pr.Number, true)
+}
+
+func (p *Platform) AddPRLabels(pr *sdk.PRParameter) error {
+	return p.addLabels("AddPRLabels", pr.Org, pr.Repo, pr.Number, true, pr.Labels)
+}
+
+func (p *Platform) DeletePRLabels(pr *sdk.PRParameter) error {
+	return p.deleteLabels("DeletePRLabels", pr.Org, pr.Repo, pr.Number, true, pr.Labels)
+}
+
+func (p *Platform) GetIssueLabels(iss *sdk.IssueParameter) (*sets.String, error) {
+	return p.getLabels("GetIssueLabels", iss.Org, iss.Repo, iss.Number, false)
+}
+
+func (p *Platform) AddIssueLabels(iss *sdk.IssueParameter) error {
+	return p.addLabels("AddIssueLabels", iss.Org, iss.Repo, iss.Number, false, iss.Labels)
+}
+
+func (p *Platform) DeleteIssueLabels(iss *sdk.IssueParameter) error {
+	return p.deleteLabels("DeleteIssueLabels", iss.Org, iss.Repo, iss.Number, false, iss.Labels)
+}
+
+func (p *Platform) AddPRComment(pr *sdk.PRParameter) error {
+	return p.addComment("AddPRComment", pr.Org, pr.Repo, pr.Number, true, pr.Comment)
+}
+
+func (p *Platform) DeletePRComment(pr *sdk.PRParameter) error {
+	return p.deleteComment("DeletePRComment", pr.Org, pr.Repo, pr.Number, true, pr.CommentID)
+}
+
+func (p *Platform) AssignPR(pr *sdk.PRParameter) error {
+	return p.assign("AssignPR", pr.Org, pr.Repo, pr.Number, true, pr.Reviewers)
+}
+
+func (p *Platform) ListOpenPRs(org, repo string) ([]string, error) {
+	return p.listOpen("ListOpenPRs", org, repo, true)
+}
+
+func (p *Platform) GetPR(pr *sdk.PRParameter) (*sdk.PullRequest, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("GetPR"); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(pr.Org, pr.Repo, pr.Number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	state := "open"
+	if v.Closed {
+		state = "closed"
+	}
+
+	return &sdk.PullRequest{
+		Number:    v.Number,
+		Title:     v.Title,
+		Body:      v.Body,
+		Author:    v.Author,
+		State:     state,
+		Base:      v.Base,
+		Head:      v.Head,
+		HeadRepo:  pr.Org + "/" + pr.Repo,
+		Draft:     v.Draft,
+		Mergeable: true,
+		Labels:    v.Labels.List(),
+	}, nil
+}
+
+func (p *Platform) GetPRChangedFiles(pr *sdk.PRParameter) ([]sdk.PRFile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("GetPRChangedFiles"); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(pr.Org, pr.Repo, pr.Number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]sdk.PRFile(nil), v.Files...), nil
+}
+
+func (p *Platform) ListPRCommits(pr *sdk.PRParameter) ([]sdk.Commit, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("ListPRCommits"); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(pr.Org, pr.Repo, pr.Number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]sdk.Commit(nil), v.Commits...), nil
+}
+
+func (p *Platform) ListPRComments(pr *sdk.PRParameter) ([]sdk.Comment, error) {
+	return p.listComments("ListPRComments", pr.Org, pr.Repo, pr.Number, true)
+}
+
+func (p *Platform) AddIssueComment(iss *sdk.IssueParameter) error {
+	return p.addComment("AddIssueComment", iss.Org, iss.Repo, iss.Number, false, iss.Comment)
+}
+
+func (p *Platform) DeleteIssueComment(iss *sdk.IssueParameter) error {
+	return p.deleteComment("DeleteIssueComment", iss.Org, iss.Repo, iss.Number, false, iss.CommentID)
+}
+
+func (p *Platform) AssignIssue(iss *sdk.IssueParameter) error {
+	return p.assign("AssignIssue", iss.Org, iss.Repo, iss.Number, false, iss.Reviewers)
+}
+
+func (p *Platform) UpdateIssueComment(iss *sdk.IssueParameter) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("UpdateIssueComment"); err != nil {
+		return err
+	}
+
+	v, err := p.item(iss.Org, iss.Repo, iss.Number, false)
+	if err != nil {
+		return err
+	}
+
+	for i := range v.Comments {
+		if v.Comments[i].ID == iss.CommentID {
+			v.Comments[i].Body = iss.Comment
+
+			return nil
+		}
+	}
+
+	return notFound(fmt.Sprintf("comment %s", iss.CommentID))
+}
+
+func (p *Platform) ListIssueComments(iss *sdk.IssueParameter) ([]sdk.Comment, error) {
+	return p.listComments("ListIssueComments", iss.Org, iss.Repo, iss.Number, false)
+}
+
+func (p *Platform) AddIssueAssignees(iss *sdk.IssueParameter) error {
+	return p.assign("AddIssueAssignees", iss.Org, iss.Repo, iss.Number, false, iss.Reviewers)
+}
+
+func (p *Platform) RemoveIssueAssignees(iss *sdk.IssueParameter) error {
+	return p.updateIssue("RemoveIssueAssignees", iss, func(v *Item) {
+		v.Assignees.Delete(iss.Reviewers...)
+	})
+}
+
+func (p *Platform) GetIssue(iss *sdk.IssueParameter) (*sdk.Issue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("GetIssue"); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(iss.Org, iss.Repo, iss.Number, false)
+	if err != nil {
+		return nil, err
+	}
+
+	state := sdk.IssueStateOpen
+	if v.Closed {
+		state = sdk.IssueStateClosed
+	}
+
+	return &sdk.Issue{
+		Number:    v.Number,
+		Title:     v.Title,
+		Body:      v.Body,
+		Author:    v.Author,
+		State:     state,
+		Assignees: v.Assignees.List(),
+		Labels:    v.Labels.List(),
+		Milestone: v.Milestone,
+		IssueType: v.IssueType,
+	}, nil
+}
+
+func (p *Platform) CloseIssue(iss *sdk.IssueParameter) error {
+	return p.updateIssue("CloseIssue", iss, func(v *Item) { v.Closed = true })
+}
+
+func (p *Platform) ReopenIssue(iss *sdk.IssueParameter) error {
+	return p.updateIssue("ReopenIssue", iss, func(v *Item) { v.Closed = false })
+}
+
+func (p *Platform) SetIssueMilestone(iss *sdk.IssueParameter) error {
+	return p.updateIssue("SetIssueMilestone", iss, func(v *Item) { v.Milestone = iss.Milestone })
+}
+
+func (p *Platform) SetIssueType(iss *sdk.IssueParameter) error {
+	return p.updateIssue("SetIssueType", iss, func(v *Item) { v.IssueType = iss.IssueType })
+}
+
+func (p *Platform) updateIssue(op string, iss *sdk.IssueParameter, update func(*Item)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	v, err := p.item(iss.Org, iss.Repo, iss.Number, false)
+	if err != nil {
+		return err
+	}
+
+	update(v)
+
+	return nil
+}
+
+func (p *Platform) ListOpenIssues(org, repo string) ([]string, error) {
+	return p.listOpen("ListOpenIssues", org, repo, false)
+}
+
+func (p *Platform) GetRepoContentsByPath(path string) ([]*sdk.ContentInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return nil, p.enter("GetRepoContentsByPath")
+}
+
+func (p *Platform) ListCollaborator(org, repo string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter("ListCollaborator"); err != nil {
+		return nil, err
+	}
+
+	r, err := p.repo(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string(nil), r.Collaborators...), nil
+}
+
+func (p *Platform) getLabels(op, org, repo, number string, isPR bool) (*sets.String, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyOf(v.Labels), nil
+}
+
+// addLabels adds the labels to the issue or PR, and creates the ones which don't
+// exist in the repo as the platform does.
+func (p *Platform) addLabels(op, org, repo, number string, isPR bool, labels []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return err
+	}
+
+	v.Labels.Insert(labels...)
+	p.repos[org+"/"+repo].Labels.Insert(labels...)
+
+	return nil
+}
+
+func (p *Platform) deleteLabels(op, org, repo, number string, isPR bool, labels []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	if len(labels) == 0 {
+		return fmt.Errorf("can not found label to remove")
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return err
+	}
+
+	v.Labels.Delete(labels...)
+
+	return nil
+}
+
+func (p *Platform) addComment(op, org, repo, number string, isPR bool, body string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return err
+	}
+
+	p.nextID++
+	v.Comments = append(v.Comments, Comment{ID: strconv.Itoa(p.nextID), Body: body})
+
+	return nil
+}
+
+func (p *Platform) listComments(op, org, repo, number string, isPR bool) ([]sdk.Comment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return nil, err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]sdk.Comment, len(v.Comments))
+	for i, c := range v.Comments {
+		r[i] = sdk.Comment{ID: c.ID, Body: c.Body, Author: c.Author}
+	}
+
+	return r, nil
+}
+
+func (p *Platform) deleteComment(op, org, repo, number string, isPR bool, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return err
+	}
+
+	for i := range v.Comments {
+		if v.Comments[i].ID == id {
+			v.Comments = append(v.Comments[:i], v.Comments[i+1:]...)
+
+			return nil
+		}
+	}
+
+	return notFound(fmt.Sprintf("comment %s", id))
+}
+
+func (p *Platform) assign(op, org, repo, number string, isPR bool, users []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return err
+	}
+
+	v, err := p.item(org, repo, number, isPR)
+	if err != nil {
+		return err
+	}
+
+	v.Assignees.Insert(users...)
+
+	return nil
+}
+
+func (p *Platform) listOpen(op, org, repo string, isPR bool) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.enter(op); err != nil {
+		return nil, err
+	}
+
+	r, err := p.repo(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	items := r.issues
+	if isPR {
+		items = r.prs
+	}
+
+	var v []string
+	for k, item := range items {
+		if !item.Closed {
+			v = append(v, k)
+		}
+	}
+
+	sort.Strings(v)
+
+	return v, nil
+}
+
+// enter records the call of op and returns the injected fault if any. p.mu must be held.
+func (p *Platform) enter(op string) error {
+	p.calls = append(p.calls, op)
+
+	f, ok := p.faults[op]
+	if !ok {
+		return nil
+	}
+
+	if f.times > 0 {
+		if f.times--; f.times == 0 {
+			delete(p.faults, op)
+		}
+	}
+
+	return f.err
+}
+
+func (p *Platform) repo(org, repo string) (*Repo, error) {
+	r, ok := p.repos[org+"/"+repo]
+	if !ok {
+		return nil, notFound(fmt.Sprintf("repo %s/%s", org, repo))
+	}
+
+	return r, nil
+}
+
+func (p *Platform) item(org, repo, number string, isPR bool) (*Item, error) {
+	r, err := p.repo(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, items := "issue", r.issues
+	if isPR {
+		kind, items = "pr", r.prs
+	}
+
+	v, ok := items[number]
+	if !ok {
+		return nil, notFound(fmt.Sprintf("%s %s of %s/%s", kind, number, org, repo))
+	}
+
+	return v, nil
+}
+
+// notFound returns the error which the platform responds when what doesn't exist.
+func notFound(what string) error {
+	return &sdk.Error{
+		Operation:  "find " + what,
+		StatusCode: http.StatusNotFound,
+		Message:    "404 Not Found",
+		Err:        ErrNotFound,
+	}
+}
+
+func newItem(number, author string) *Item {
+	return &Item{
+		Number:    number,
+		Author:    author,
+		Labels:    sets.NewString(),
+		Assignees: sets.NewString(),
+	}
+}
+
+func copyOf(s sets.String) *sets.String {
+	v := sets.NewString(s.UnsortedList()...)
+
+	return &v
+}
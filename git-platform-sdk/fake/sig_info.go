@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"strings"
+	"sync"
+)
+
+// SigRepo is the sig info of a repo.
+type SigRepo struct {
+	Sig         string
+	Maintainers []string
+	Committers  []string
+}
+
+// SigInfo is an in-memory sig-info-cache, the repo which is not added belongs to no sig.
+type SigInfo struct {
+	mu      sync.Mutex
+	repos   map[string]SigRepo
+	sigs    map[string]string
+	content map[string]any
+	// PingErr is returned by Ping if it is set
+	PingErr error
+}
+
+// NewSigInfo creates an empty sig-info-cache.
+func NewSigInfo() *SigInfo {
+	return &SigInfo{repos: map[string]SigRepo{}, sigs: map[string]string{}}
+}
+
+// AddSig adds the sigs which exist besides the ones of repos.
+func (s *SigInfo) AddSig(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		s.sigs[strings.ToLower(name)] = name
+	}
+}
+
+// AddRepo sets the sig info of the repo.
+func (s *SigInfo) AddRepo(org, repo string, v SigRepo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repos[org+"/"+repo] = v
+
+	if v.Sig != "" {
+		s.sigs[strings.ToLower(v.Sig)] = v.Sig
+	}
+}
+
+// SetContent sets the content which is returned by GetContentByPath.
+func (s *SigInfo) SetContent(v map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.content = v
+}
+
+func (s *SigInfo) repo(org, repo string) SigRepo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.repos[org+"/"+repo]
+}
+
+func (s *SigInfo) GetSigNameByOrgRepo(org, repo string) (string, error) {
+	return s.repo(org, repo).Sig, nil
+}
+
+func (s *SigInfo) GetSigName(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sigs[strings.ToLower(name)], nil
+}
+
+func (s *SigInfo) GetRepositoryMaintainerByOrgRepo(org, repo string) ([]string, error) {
+	return append([]string(nil), s.repo(org, repo).Maintainers...), nil
+}
+
+func (s *SigInfo) GetRepositoryCommitterByOrgRepo(org, repo string) ([]string, error) {
+	return append([]string(nil), s.repo(org, repo).Committers...), nil
+}
+
+func (s *SigInfo) GetContentByPath(path string) (*map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.content == nil {
+		return nil, nil
+	}
+
+	v := s.content
+
+	return &v, nil
+}
+
+func (s *SigInfo) Ping() error {
+	return s.PingErr
+}
@@ -0,0 +1,213 @@
+package sdkadapter
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// The models below are the responses of the apis called without the generated client,
+// they keep only the fields used.
+
+type giteeUser struct {
+	Login string `json:"login"`
+}
+
+type giteeBranch struct {
+	Ref  string `json:"ref"`
+	Sha  string `json:"sha"`
+	Repo struct {
+		FullName string `json:"full_name"`
+	} `json:"repo"`
+}
+
+type giteePullRequest struct {
+	Number    int32       `json:"number"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	State     string      `json:"state"`
+	Draft     bool        `json:"draft"`
+	Mergeable bool        `json:"mergeable"`
+	User      giteeUser   `json:"user"`
+	Base      giteeBranch `json:"base"`
+	Head      giteeBranch `json:"head"`
+	Labels    []Label     `json:"labels"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func (v *giteePullRequest) toPullRequest() *PullRequest {
+	labels := make([]string, len(v.Labels))
+	for i := range v.Labels {
+		labels[i] = v.Labels[i].Name
+	}
+
+	return &PullRequest{
+		Number:    strconv.Itoa(int(v.Number)),
+		Title:     v.Title,
+		Body:      v.Body,
+		Author:    v.User.Login,
+		State:     v.State,
+		Base:      v.Base.Ref,
+		BaseSHA:   v.Base.Sha,
+		Head:      v.Head.Ref,
+		HeadSHA:   v.Head.Sha,
+		HeadRepo:  v.Head.Repo.FullName,
+		Draft:     v.Draft,
+		Mergeable: v.Mergeable,
+		Labels:    labels,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
+
+type giteePRFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	// gitee responds the numbers as strings
+	Additions lenientInt `json:"additions"`
+	Deletions lenientInt `json:"deletions"`
+	// PreviousFilename is set by github style apis
+	PreviousFilename string `json:"previous_filename"`
+	Patch            struct {
+		OldPath     string `json:"old_path"`
+		NewFile     bool   `json:"new_file"`
+		RenamedFile bool   `json:"renamed_file"`
+		DeletedFile bool   `json:"deleted_file"`
+	} `json:"patch"`
+}
+
+func (v *giteePRFile) toPRFile() PRFile {
+	f := PRFile{
+		Filename:         v.Filename,
+		PreviousFilename: v.PreviousFilename,
+		Status:           v.Status,
+		Additions:        int(v.Additions),
+		Deletions:        int(v.Deletions),
+	}
+
+	switch {
+	case v.Patch.RenamedFile:
+		f.Status = FileStatusRenamed
+		f.PreviousFilename = v.Patch.OldPath
+	case v.Patch.NewFile:
+		f.Status = FileStatusAdded
+	case v.Patch.DeletedFile:
+		f.Status = FileStatusRemoved
+	case f.Status == "":
+		f.Status = FileStatusModified
+	}
+
+	return f
+}
+
+type giteeCommit struct {
+	Sha    string     `json:"sha"`
+	Author *giteeUser `json:"author"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+func (v *giteeCommit) toCommit() Commit {
+	c := Commit{
+		SHA:         v.Sha,
+		Message:     v.Commit.Message,
+		AuthorName:  v.Commit.Author.Name,
+		AuthorEmail: v.Commit.Author.Email,
+	}
+
+	if v.Author != nil {
+		c.Author = v.Author.Login
+	}
+
+	return c
+}
+
+type giteeIssue struct {
+	Number        string      `json:"number"`
+	Title         string      `json:"title"`
+	Body          string      `json:"body"`
+	State         string      `json:"state"`
+	User          giteeUser   `json:"user"`
+	Assignee      *giteeUser  `json:"assignee"`
+	Collaborators []giteeUser `json:"collaborators"`
+	Labels        []Label     `json:"labels"`
+	Milestone     *struct {
+		Number int32  `json:"number"`
+		Title  string `json:"title"`
+	} `json:"milestone"`
+	IssueType string    `json:"issue_type"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (v *giteeIssue) toIssue() *Issue {
+	iss := &Issue{
+		Number:    v.Number,
+		Title:     v.Title,
+		Body:      v.Body,
+		Author:    v.User.Login,
+		State:     v.State,
+		IssueType: v.IssueType,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+
+	if v.Assignee != nil && v.Assignee.Login != "" {
+		iss.Assignees = append(iss.Assignees, v.Assignee.Login)
+	}
+	for i := range v.Collaborators {
+		iss.Assignees = append(iss.Assignees, v.Collaborators[i].Login)
+	}
+
+	for i := range v.Labels {
+		iss.Labels = append(iss.Labels, v.Labels[i].Name)
+	}
+
+	if v.Milestone != nil {
+		iss.Milestone = strconv.Itoa(int(v.Milestone.Number))
+		iss.MilestoneTitle = v.Milestone.Title
+	}
+
+	return iss
+}
+
+type giteeComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      giteeUser `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (v *giteeComment) toComment() Comment {
+	return Comment{
+		ID:        strconv.FormatInt(v.ID, 10),
+		Body:      v.Body,
+		Author:    v.User.Login,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
+
+// lenientInt is an integer which may be encoded as a string.
+type lenientInt int
+
+func (v *lenientInt) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*v = 0
+
+		return nil
+	}
+
+	n, err := strconv.Atoi(string(b))
+	*v = lenientInt(n)
+
+	return err
+}
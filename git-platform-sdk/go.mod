@@ -5,6 +5,7 @@ go 1.20
 require (
 	github.com/antihax/optional v1.0.0
 	github.com/opensourceways/go-gitee v0.0.0-20240305060727-0df28a4f60c0
+	golang.org/x/time v0.3.0
 	k8s.io/apimachinery v0.25.3
 )
 
@@ -0,0 +1,155 @@
+package sdkadapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// LabelChanges are the changes made to the labels of repo by SyncRepoLabels.
+type LabelChanges struct {
+	Created []string
+	Updated []string
+	// Renamed maps the old names to the new ones
+	Renamed map[string]string
+	Deleted []string
+}
+
+// IsEmpty reports whether nothing is changed.
+func (c *LabelChanges) IsEmpty() bool {
+	return len(c.Created) == 0 && len(c.Updated) == 0 && len(c.Renamed) == 0 && len(c.Deleted) == 0
+}
+
+// SyncRepoLabels makes the labels of repo be the desired ones. The label which doesn't exist
+// is renamed from one of its aliases if any, or created otherwise; the one whose color
+// differs is updated. The labels not desired are deleted only if prune is set.
+// It goes on when a change fails, and returns the changes made and all the errors.
+func SyncRepoLabels(cli LabelClient, org, repo string, desired []Label, prune bool) (LabelChanges, error) {
+	changes := LabelChanges{Renamed: map[string]string{}}
+
+	if err := ValidateLabels(desired); err != nil {
+		return changes, err
+	}
+
+	ls, err := cli.ListRepoLabels(&LabelParameter{Org: org, Repo: repo})
+	if err != nil {
+		return changes, err
+	}
+
+	current := make(map[string]*Label, len(ls))
+	for i := range ls {
+		current[ls[i].Name] = &ls[i]
+	}
+
+	var errs []error
+	kept := sets.NewString()
+
+	for i := range desired {
+		want := &desired[i]
+		lp := &LabelParameter{
+			Org:         org,
+			Repo:        repo,
+			Name:        want.Name,
+			Color:       want.Color,
+			Description: want.Description,
+		}
+
+		if cur, ok := current[want.Name]; ok {
+			kept.Insert(want.Name)
+
+			if !labelDiffers(cur, want) {
+				continue
+			}
+
+			if err := cli.UpdateRepoLabel(lp); err != nil {
+				errs = append(errs, err)
+			} else {
+				changes.Updated = append(changes.Updated, want.Name)
+			}
+
+			continue
+		}
+
+		if alias := aliasOf(want, current, kept); alias != "" {
+			kept.Insert(alias)
+
+			lp.Name, lp.NewName = alias, want.Name
+			if err := cli.UpdateRepoLabel(lp); err != nil {
+				errs = append(errs, err)
+			} else {
+				changes.Renamed[alias] = want.Name
+			}
+
+			continue
+		}
+
+		if err := cli.AddRepoLabels(lp); err != nil && !IsAlreadyExists(err) {
+			errs = append(errs, err)
+		} else {
+			changes.Created = append(changes.Created, want.Name)
+		}
+	}
+
+	if !prune {
+		return changes, errors.Join(errs...)
+	}
+
+	for i := range ls {
+		name := ls[i].Name
+		if kept.Has(name) {
+			continue
+		}
+
+		if err := cli.DeleteRepoLabel(&LabelParameter{Org: org, Repo: repo, Name: name}); err != nil {
+			errs = append(errs, err)
+		} else {
+			changes.Deleted = append(changes.Deleted, name)
+		}
+	}
+
+	return changes, errors.Join(errs...)
+}
+
+// ValidateLabels checks that each name, including the aliases, is used only once.
+func ValidateLabels(ls []Label) error {
+	names := sets.NewString()
+
+	for i := range ls {
+		for _, name := range append([]string{ls[i].Name}, ls[i].Aliases...) {
+			if name == "" {
+				return fmt.Errorf("the name of label can not be empty")
+			}
+
+			if names.Has(name) {
+				return fmt.Errorf("the label %s is duplicate", name)
+			}
+
+			names.Insert(name)
+		}
+	}
+
+	return nil
+}
+
+// labelDiffers compares the color if it is specified by want. The description is not
+// compared, since it can't be updated on the platform which doesn't support it, such as gitee.
+func labelDiffers(cur, want *Label) bool {
+	return want.Color != "" && normalizeColor(want.Color) != normalizeColor(cur.Color)
+}
+
+// aliasOf returns the alias of want which is an existing label not kept yet.
+func aliasOf(want *Label, current map[string]*Label, kept sets.String) string {
+	for _, alias := range want.Aliases {
+		if _, ok := current[alias]; ok && !kept.Has(alias) {
+			return alias
+		}
+	}
+
+	return ""
+}
+
+func normalizeColor(c string) string {
+	return strings.ToLower(strings.TrimPrefix(c, "#"))
+}
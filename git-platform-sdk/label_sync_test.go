@@ -0,0 +1,69 @@
+package sdkadapter_test
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "git-platform-sdk"
+	"git-platform-sdk/fake"
+)
+
+func TestSyncRepoLabels(t *testing.T) {
+	platform := fake.New()
+	r := platform.AddRepo("org", "repo")
+	r.Labels.Insert("bug", "kind/feature", "stale")
+	r.LabelDetails["bug"] = sdk.Label{Name: "bug", Color: "#FF0000"}
+
+	// the description is not compared, since gitee can't update it
+	desired := []sdk.Label{
+		{Name: "bug", Color: "ff0000", Description: "something is wrong"},
+		{Name: "kind/enhancement", Color: "00ff00", Aliases: []string{"kind/feature"}},
+		{Name: "good first issue", Description: "easy to start"},
+	}
+
+	changes, err := sdk.SyncRepoLabels(platform, "org", "repo", desired, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expect := sdk.LabelChanges{
+		Created: []string{"good first issue"},
+		Renamed: map[string]string{"kind/feature": "kind/enhancement"},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Errorf("Expected changes %+v, got %+v", expect, changes)
+	}
+
+	if v := r.Labels.List(); !reflect.DeepEqual(v, []string{"bug", "good first issue", "kind/enhancement", "stale"}) {
+		t.Errorf("Unexpected labels: %v", v)
+	}
+
+	if c := r.LabelDetails["kind/enhancement"].Color; c != "00ff00" {
+		t.Errorf("Expected the renamed label to be updated, got color %s", c)
+	}
+
+	// it is idempotent, and deletes the labels not desired if pruning
+	changes, err = sdk.SyncRepoLabels(platform, "org", "repo", desired, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(changes.Deleted, []string{"stale"}) || len(changes.Created)+len(changes.Updated)+len(changes.Renamed) != 0 {
+		t.Errorf("Expected only the stale label to be deleted, got %+v", changes)
+	}
+}
+
+func TestSyncRepoLabelsInvalid(t *testing.T) {
+	platform := fake.New()
+	platform.AddRepo("org", "repo")
+
+	desired := []sdk.Label{{Name: "bug"}, {Name: "defect", Aliases: []string{"bug"}}}
+
+	if _, err := sdk.SyncRepoLabels(platform, "org", "repo", desired, false); err == nil {
+		t.Errorf("Expected error for the duplicate label")
+	}
+
+	if calls := platform.Calls(); len(calls) != 0 {
+		t.Errorf("Expected no call for the invalid labels, got %v", calls)
+	}
+}
@@ -0,0 +1,61 @@
+package sdkadapter
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// APICallObserver observes each call to the platform api. The operation is the name
+// of method of ClientTarget which makes the call, and the statusCode is 0 if no response.
+type APICallObserver func(operation string, statusCode int, duration time.Duration)
+
+var apiCallObserver APICallObserver
+
+// SetAPICallObserver sets the observer of platform api calls, it must be called before any call.
+func SetAPICallObserver(o APICallObserver) {
+	apiCallObserver = o
+}
+
+type operationKey struct{}
+
+// opContext returns the context of the api call made by the method op.
+func (c *ClientTarget) opContext(op string) context.Context {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// operationOf returns the name of method which makes the api call with ctx.
+func operationOf(ctx context.Context) string {
+	if op, _ := ctx.Value(operationKey{}).(string); op != "" {
+		return op
+	}
+
+	return "unknown"
+}
+
+// observedTransport reports every request to the apiCallObserver.
+type observedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.base.RoundTrip(req)
+
+	if o := apiCallObserver; o != nil {
+		code := 0
+		if err == nil && resp != nil {
+			code = resp.StatusCode
+		}
+
+		o(operationOf(req.Context()), code, time.Since(start))
+	}
+
+	return resp, err
+}
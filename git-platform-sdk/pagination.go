@@ -0,0 +1,89 @@
+package sdkadapter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// perPage is the page size of list calls, which is the maximum the platforms allow.
+const perPage int32 = 100
+
+// maxPages is the maximum number of pages to list, it stops the loop if the platform
+// keeps reporting a next page.
+const maxPages int32 = 100
+
+// pageLister lists a page of items, it returns the number of items in the page
+// and the response which tells whether there is a next page.
+type pageLister func(page, perPage int32) (int, *http.Response, error)
+
+// listPages calls list page by page until the last page, or fails if there are more than maxPages.
+// It returns the response of the page which fails if any.
+func listPages(list pageLister) (*http.Response, error) {
+	for page := int32(1); page <= maxPages; page++ {
+		n, resp, err := list(page, perPage)
+		if err != nil {
+			return resp, err
+		}
+
+		if !hasNextPage(resp, page, perPage, n) {
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there are more than %d pages", maxPages)
+}
+
+// hasNextPage tells whether there is a page after the current one by the Link header
+// (github style), the total_page or total_count header (gitee style) in turn. It falls
+// back to check whether the current page is full if the response has none of them.
+func hasNextPage(resp *http.Response, page, size int32, n int) bool {
+	if n == 0 {
+		return false
+	}
+
+	if resp != nil {
+		h := resp.Header
+
+		if v := h.Get("Link"); v != "" {
+			return hasNextLink(v)
+		}
+
+		if v, err := strconv.Atoi(h.Get("total_page")); err == nil {
+			return int(page) < v
+		}
+
+		if v, err := strconv.Atoi(h.Get("total_count")); err == nil {
+			return int(page)*int(size) < v
+		}
+	}
+
+	return n >= int(size)
+}
+
+// hasNextLink checks whether the Link header, such as `<url>; rel="next", <url>; rel="last"`, has the next page.
+func hasNextLink(link string) bool {
+	for _, part := range strings.Split(link, ",") {
+		for _, param := range strings.Split(part, ";")[1:] {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || k != "rel" {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.Trim(v, `"`)) {
+				if rel == "next" {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// getPage gets a page of the list api which the generated client can't page through,
+// and decodes it to v.
+func (c *ClientTarget) getPage(op, path string, page, size int32, v any) (*http.Response, error) {
+	return c.get(op, fmt.Sprintf("%s?page=%d&per_page=%d", path, page, size), v)
+}
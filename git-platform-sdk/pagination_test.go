@@ -0,0 +1,128 @@
+package sdkadapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newPagedServer serves total labels by pages, and sets the pagination headers by style.
+func newPagedServer(t *testing.T, total int, style string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if page < 1 || size < 1 {
+			t.Errorf("invalid page:%d or per_page:%d", page, size)
+		}
+
+		pages := (total + size - 1) / size
+
+		switch style {
+		case "link":
+			if page < pages {
+				w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next", <%s?page=%d>; rel="last"`,
+					r.URL.Path, page+1, r.URL.Path, pages))
+			} else {
+				w.Header().Set("Link", fmt.Sprintf(`<%s?page=1>; rel="first"`, r.URL.Path))
+			}
+		case "total_page":
+			w.Header().Set("total_page", strconv.Itoa(pages))
+		case "total_count":
+			w.Header().Set("total_count", strconv.Itoa(total))
+		}
+
+		var ls []map[string]string
+		for i := (page - 1) * size; i < total && i < page*size; i++ {
+			ls = append(ls, map[string]string{"name": fmt.Sprintf("label-%d", i)})
+		}
+
+		_ = json.NewEncoder(w).Encode(ls)
+	}))
+}
+
+func TestGetRepoLabelsPaginated(t *testing.T) {
+	testCases := []struct {
+		description string
+		style       string
+		total       int
+	}{
+		{description: "link header", style: "link", total: 250},
+		{description: "total page header", style: "total_page", total: 250},
+		{description: "total count header", style: "total_count", total: 201},
+		{description: "no header and full last page", style: "", total: 200},
+		{description: "no header and short last page", style: "", total: 150},
+		{description: "single page", style: "link", total: 3},
+		{description: "no labels", style: "total_page", total: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			srv := newPagedServer(t, tc.total, tc.style)
+			defer srv.Close()
+
+			c := &ClientTarget{hc: srv.Client(), basePath: srv.URL}
+
+			labels, err := c.GetRepoLabels(&LabelParameter{Org: "org", Repo: "repo"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if labels.Len() != tc.total {
+				t.Errorf("Expected %d labels, got %d", tc.total, labels.Len())
+			}
+
+			if tc.total > 0 && !labels.Has(fmt.Sprintf("label-%d", tc.total-1)) {
+				t.Errorf("Expected the label of last page")
+			}
+		})
+	}
+}
+
+func TestGetIssueLabelsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := &ClientTarget{hc: srv.Client(), basePath: srv.URL}
+
+	if _, err := c.GetIssueLabels(&IssueParameter{Org: "org", Repo: "repo", Number: "I1"}); err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestListPagesLimit(t *testing.T) {
+	var calls int32
+
+	// the platform always reports a next page
+	_, err := listPages(func(page, size int32) (int, *http.Response, error) {
+		calls++
+
+		return int(size), nil, nil
+	})
+
+	if err == nil || calls != maxPages {
+		t.Errorf("Expected error after %d pages, got %d pages and %v", maxPages, calls, err)
+	}
+}
+
+func TestHasNextLink(t *testing.T) {
+	testCases := []struct {
+		link     string
+		expected bool
+	}{
+		{link: `<https://a/b?page=2>; rel="next", <https://a/b?page=5>; rel="last"`, expected: true},
+		{link: `<https://a/b?page=1>; rel="first", <https://a/b?page=4>; rel="prev"`, expected: false},
+		{link: `<https://a/b?page=2>; rel="next last"`, expected: true},
+		{link: `<https://a/b?page=2>`, expected: false},
+	}
+
+	for _, tc := range testCases {
+		if v := hasNextLink(tc.link); v != tc.expected {
+			t.Errorf("link %q: expected %v, got %v", tc.link, tc.expected, v)
+		}
+	}
+}
@@ -1 +1,82 @@
 package sdkadapter
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type payloadUser struct {
+	Login string `json:"login"`
+}
+
+type eventPayload struct {
+	Action  string `json:"action"`
+	Comment struct {
+		Body string      `json:"body"`
+		User payloadUser `json:"user"`
+	} `json:"comment"`
+	Issue *struct {
+		Number json.RawMessage `json:"number"`
+		User   payloadUser     `json:"user"`
+	} `json:"issue"`
+	PullRequest *struct {
+		Number json.RawMessage `json:"number"`
+		User   payloadUser     `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ParsePayload fills the fields of an issue, PR or comment event from its payload.
+// The event belongs to a PR if PRNumber is set after parsing, otherwise it belongs to an issue.
+// Only the empty fields are filled, the ones set by the converter of webhook are kept.
+func (ge *GenericEvent) ParsePayload() error {
+	if ge.Payload == nil {
+		return nil
+	}
+
+	var p eventPayload
+	if err := json.Unmarshal(ge.Payload, &p); err != nil {
+		return err
+	}
+
+	if ge.Action == "" {
+		ge.Action = p.Action
+	}
+
+	if ge.Org == "" || ge.Repo == "" {
+		if v := strings.Split(p.Repository.FullName, "/"); len(v) == 2 {
+			ge.Org, ge.Repo = v[0], v[1]
+		}
+	}
+
+	if p.PullRequest != nil {
+		setIfEmpty(&ge.PRNumber, rawNumber(p.PullRequest.Number))
+		setIfEmpty(&ge.PRAuthor, p.PullRequest.User.Login)
+		setIfEmpty(&ge.PRComment, p.Comment.Body)
+		setIfEmpty(&ge.PRCommenter, p.Comment.User.Login)
+
+		return nil
+	}
+
+	if p.Issue != nil {
+		setIfEmpty(&ge.IssueNumber, rawNumber(p.Issue.Number))
+		setIfEmpty(&ge.IssueAuthor, p.Issue.User.Login)
+		setIfEmpty(&ge.IssueComment, p.Comment.Body)
+		setIfEmpty(&ge.IssueCommenter, p.Comment.User.Login)
+	}
+
+	return nil
+}
+
+func setIfEmpty(field *string, v string) {
+	if *field == "" {
+		*field = v
+	}
+}
+
+// rawNumber converts the number which may be a json string or a json number to string.
+func rawNumber(v json.RawMessage) string {
+	return strings.Trim(string(v), `"`)
+}
@@ -0,0 +1,250 @@
+package sdkadapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+// RateLimit is the limit of the calls to the platform api made with the same token.
+// The zero value of each field means the default one.
+type RateLimit struct {
+	// QPS is the number of calls per second, no limit if it is negative
+	QPS float64
+	// Burst is the maximum number of calls at once
+	Burst int
+	// MaxRetries is the maximum number of retries of a call which responds with 429,
+	// or 5xx if the method is idempotent. No retry if it is negative
+	MaxRetries int
+}
+
+var (
+	defaultRateLimit = RateLimit{QPS: 10, Burst: 20, MaxRetries: 3}
+	rateLimit        = defaultRateLimit
+)
+
+// SetRateLimit sets the limit of calls to the platform api, it must be called before GetClientInstance.
+func SetRateLimit(rl RateLimit) {
+	if rl.QPS == 0 {
+		rl.QPS = defaultRateLimit.QPS
+	}
+
+	if rl.Burst <= 0 {
+		rl.Burst = defaultRateLimit.Burst
+	}
+
+	if rl.MaxRetries == 0 {
+		rl.MaxRetries = defaultRateLimit.MaxRetries
+	} else if rl.MaxRetries < 0 {
+		rl.MaxRetries = 0
+	}
+
+	rateLimit = rl
+}
+
+// RateLimitError means the call is rejected because of the rate limit of the platform
+// even after retrying. The caller can retry it after RetryAfter.
+type RateLimitError struct {
+	Operation  string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit of platform api exceeded when calling %s, retry after %s", e.Operation, e.RetryAfter)
+}
+
+// tokenBucket limits the calls made with a token.
+type tokenBucket struct {
+	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// pausedUntil is the time when the quota told by the platform resets
+	pausedUntil time.Time
+}
+
+var (
+	bucketsLock sync.Mutex
+	buckets     = map[string]*tokenBucket{}
+)
+
+// bucketFor returns the bucket of token which is shared by all the calls made with it.
+func bucketFor(token string) *tokenBucket {
+	bucketsLock.Lock()
+	defer bucketsLock.Unlock()
+
+	if b, ok := buckets[token]; ok {
+		return b
+	}
+
+	limit := rate.Inf
+	if rateLimit.QPS > 0 {
+		limit = rate.Limit(rateLimit.QPS)
+	}
+
+	b := &tokenBucket{limiter: rate.NewLimiter(limit, rateLimit.Burst)}
+	buckets[token] = b
+
+	return b
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	d := time.Until(b.pausedUntil)
+	b.mu.Unlock()
+
+	if err := sleep(ctx, d); err != nil {
+		return err
+	}
+
+	return b.limiter.Wait(ctx)
+}
+
+// update pauses the calls until the quota resets if the platform says it is used up.
+func (b *tokenBucket) update(h http.Header) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t := time.Unix(reset, 0)
+
+	b.mu.Lock()
+	if t.After(b.pausedUntil) {
+		b.pausedUntil = t
+	}
+	b.mu.Unlock()
+}
+
+// retryTransport throttles the requests by the bucket and retries them on 429, and on 5xx
+// if the method is idempotent. The write may have been done when 5xx responds, such as
+// 502 from a gateway, so resending a POST may duplicate a comment.
+type retryTransport struct {
+	base       http.RoundTripper
+	bucket     *tokenBucket
+	maxRetries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	// the request can't be resent if its body can't be read again
+	replayable := req.Body == nil || req.GetBody != nil
+	backoff := initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		if err := t.bucket.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		t.bucket.update(resp.Header)
+
+		code := resp.StatusCode
+		if code != http.StatusTooManyRequests && (code < http.StatusInternalServerError || !isIdempotent(req.Method)) {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp.Header, backoff)
+
+		if attempt >= t.maxRetries || !replayable {
+			if code != http.StatusTooManyRequests {
+				return resp, nil
+			}
+
+			discard(resp)
+
+			return nil, &RateLimitError{Operation: operationOf(ctx), RetryAfter: wait}
+		}
+
+		discard(resp)
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter parses the Retry-After header which is either seconds or a http date.
+func retryAfter(h http.Header, def time.Duration) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return def
+	}
+
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+
+		return 0
+	}
+
+	return def
+}
+
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
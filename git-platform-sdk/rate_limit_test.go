@@ -0,0 +1,70 @@
+package sdkadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRetryTransport(t *testing.T) {
+	testCases := []struct {
+		description string
+		method      string
+		code        int
+		calls       int32
+	}{
+		{description: "GET is retried on 5xx", method: http.MethodGet, code: http.StatusBadGateway, calls: 3},
+		{description: "POST is not retried on 5xx", method: http.MethodPost, code: http.StatusBadGateway, calls: 1},
+		{description: "PATCH is not retried on 5xx", method: http.MethodPatch, code: http.StatusServiceUnavailable, calls: 1},
+		{description: "POST is retried on 429", method: http.MethodPost, code: http.StatusTooManyRequests, calls: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tc.code)
+			}))
+			defer srv.Close()
+
+			cli := &http.Client{Transport: &retryTransport{
+				base:       http.DefaultTransport,
+				bucket:     &tokenBucket{limiter: rate.NewLimiter(rate.Inf, 1)},
+				maxRetries: 2,
+			}}
+
+			req, err := http.NewRequest(tc.method, srv.URL, strings.NewReader(`{"body":"welcome"}`))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if resp, err := cli.Do(req); err == nil {
+				resp.Body.Close()
+			}
+
+			if calls != tc.calls {
+				t.Errorf("expected %d calls, got %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestSetRateLimitDefaults(t *testing.T) {
+	defer SetRateLimit(defaultRateLimit)
+
+	SetRateLimit(RateLimit{})
+	if rateLimit != defaultRateLimit {
+		t.Errorf("expected the default rate limit, got %+v", rateLimit)
+	}
+
+	SetRateLimit(RateLimit{QPS: -1, MaxRetries: -1})
+	if rateLimit.QPS != -1 || rateLimit.Burst != defaultRateLimit.Burst || rateLimit.MaxRetries != 0 {
+		t.Errorf("unexpected rate limit %+v", rateLimit)
+	}
+}
@@ -0,0 +1,61 @@
+package sdkadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// get calls the api of path which the generated client doesn't support well, and decodes the response to v.
+func (c *ClientTarget) get(op, path string, v any) (*http.Response, error) {
+	return c.call(op, http.MethodGet, path, nil, v)
+}
+
+// patch sends body to the api of path in json, it is used when the parameter
+// of generated client can't express the change, such as clearing a field.
+func (c *ClientTarget) patch(op, path string, body, v any) (*http.Response, error) {
+	return c.call(op, http.MethodPatch, path, body, v)
+}
+
+func (c *ClientTarget) call(op, method, path string, body, v any) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(c.opContext(op), method, c.basePath+path, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if code := resp.StatusCode; code < 200 || code > 299 {
+		rb, _ := io.ReadAll(resp.Body)
+
+		return resp, newStatusError(resp, rb)
+	}
+
+	if v == nil {
+		return resp, nil
+	}
+
+	return resp, json.NewDecoder(resp.Body).Decode(v)
+}
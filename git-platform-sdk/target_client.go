@@ -2,7 +2,9 @@ package sdkadapter
 
 import (
 	"context"
+	"net/http"
 	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
 
@@ -11,27 +13,69 @@ import (
 )
 
 type ClientTarget struct {
-	ac *gitee.APIClient
+	ac     *gitee.APIClient
+	ctx    context.Context
+	labels *repoLabelCache
+
+	// hc and basePath are used to call the api which the generated client doesn't support well
+	hc       *http.Client
+	basePath string
+}
+
+// WithContext returns a copy of client whose calls are made with ctx.
+func (c *ClientTarget) WithContext(ctx context.Context) Client {
+	v := *c
+	v.ctx = ctx
+
+	return &v
 }
 
 var ct *ClientTarget
 var onceClient sync.Once
 var token string
 
+// transportWrapper wraps the transport which sends the requests to the platform.
+var transportWrapper func(http.RoundTripper) http.RoundTripper
+
+// WrapTransport sets the wrapper of transport, such as a recorder of the requests.
+// It must be called before GetClientInstance.
+func WrapTransport(w func(http.RoundTripper) http.RoundTripper) {
+	transportWrapper = w
+}
+
 func initialClient() {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: token,
 	})
 	tc := oauth2.NewClient(context.Background(), ts)
+	if transportWrapper != nil {
+		tc.Transport = transportWrapper(tc.Transport)
+	}
+
+	tc.Transport = newETagTransport(&retryTransport{
+		base:       &observedTransport{base: tc.Transport},
+		bucket:     bucketFor(token),
+		maxRetries: rateLimit.MaxRetries,
+	})
 
+	ct = NewClientTarget(tc, "https://gitee.com/api")
+}
+
+// NewClientTarget creates a client which sends the requests to the platform at basePath by hc.
+// It is used by tests, GetClientInstance should be used otherwise.
+func NewClientTarget(hc *http.Client, basePath string) *ClientTarget {
 	cfg := &gitee.Configuration{
-		BasePath:      "https://gitee.com/api",
+		BasePath:      basePath,
 		DefaultHeader: make(map[string]string),
 		UserAgent:     "robot",
-		HTTPClient:    tc,
+		HTTPClient:    hc,
 	}
-	ct = &ClientTarget{
-		ac: gitee.NewAPIClient(cfg),
+
+	return &ClientTarget{
+		ac:       gitee.NewAPIClient(cfg),
+		labels:   newRepoLabelCache(),
+		hc:       hc,
+		basePath: basePath,
 	}
 }
 
@@ -42,24 +86,44 @@ func GetClientInstance(t []byte) *ClientTarget {
 }
 
 type LabelParameter struct {
-	Org    string
-	Repo   string
-	Name   string
-	Color  string
-	Extras any
+	Org   string
+	Repo  string
+	Name  string
+	Color string
+	// Description is ignored by the platform which doesn't support it, such as gitee
+	Description string
+	// NewName is the name to rename the label to when updating it
+	NewName string
+	Extras  any
+}
+
+// Label is a label of repo or org.
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Aliases are the names which the label had before, it is renamed from one of them if it doesn't exist
+	Aliases []string `json:"aliases,omitempty"`
 }
 
 type LabelClient interface {
 	GetRepoLabels(lp *LabelParameter) (*sets.String, error)
 	AddRepoLabels(lp *LabelParameter) error
+	ListRepoLabels(lp *LabelParameter) ([]Label, error)
+	UpdateRepoLabel(lp *LabelParameter) error
+	DeleteRepoLabel(lp *LabelParameter) error
+
+	// ListOrgLabels lists the labels shared by all the repos of org, which are the enterprise labels of gitee
+	ListOrgLabels(org string) ([]Label, error)
+	AddOrgLabel(lp *LabelParameter) error
 
 	GetPRLabels(pr *PRParameter) (*sets.String, error)
 	AddPRLabels(pr *PRParameter) error
 	DeletePRLabels(pr *PRParameter) error
 
-	//GetIssueLabels(iss *IssueParameter) (sets.String, error)
+	GetIssueLabels(iss *IssueParameter) (*sets.String, error)
 	AddIssueLabels(iss *IssueParameter) error
-	//RemoveIssueLabels(iss *PRParameter) error
+	DeleteIssueLabels(iss *IssueParameter) error
 }
 
 type PRParameter struct {
@@ -79,8 +143,74 @@ type PRClient interface {
 	DeletePRComment(pr *PRParameter) error
 
 	AssignPR(pr *PRParameter) error
+
+	ListOpenPRs(org, repo string) ([]string, error)
+
+	GetPR(pr *PRParameter) (*PullRequest, error)
+	GetPRChangedFiles(pr *PRParameter) ([]PRFile, error)
+	ListPRCommits(pr *PRParameter) ([]Commit, error)
+	ListPRComments(pr *PRParameter) ([]Comment, error)
 }
 
+// PullRequest is the details of a PR.
+type PullRequest struct {
+	Number string
+	Title  string
+	Body   string
+	Author string
+	State  string
+	// Base is the branch which the PR is merged into
+	Base    string
+	BaseSHA string
+	// Head is the branch which the PR comes from
+	Head    string
+	HeadSHA string
+	// HeadRepo is the full name of repo which the PR comes from, it is a fork if it differs from the repo of PR
+	HeadRepo  string
+	Draft     bool
+	Mergeable bool
+	Labels    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PRFile is a file changed by the PR.
+type PRFile struct {
+	Filename string
+	// PreviousFilename is the name before the file is renamed, it is empty if not renamed
+	PreviousFilename string
+	// Status is one of added, modified, removed and renamed
+	Status    string
+	Additions int
+	Deletions int
+}
+
+// Commit is a commit of PR.
+type Commit struct {
+	SHA     string
+	Message string
+	// Author is the account of commit author on the platform, it is empty if the email is not bound to any
+	Author      string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// Comment is a comment of issue or PR.
+type Comment struct {
+	ID        string
+	Body      string
+	Author    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	FileStatusAdded    = "added"
+	FileStatusModified = "modified"
+	FileStatusRemoved  = "removed"
+	FileStatusRenamed  = "renamed"
+)
+
 type IssueParameter struct {
 	Org       string
 	Repo      string
@@ -88,7 +218,11 @@ type IssueParameter struct {
 	Labels    []string
 	Comment   string
 	CommentID string
+	// Reviewers are the assignees of issue
 	Reviewers []string
+	// Milestone is the number of milestone, the issue is removed from its milestone if it is empty
+	Milestone string
+	IssueType string
 	Payload   any
 	Extras    any
 }
@@ -96,6 +230,46 @@ type IssueParameter struct {
 type IssueClient interface {
 	AddIssueComment(iss *IssueParameter) error
 	DeleteIssueComment(iss *IssueParameter) error
+	UpdateIssueComment(iss *IssueParameter) error
+	ListIssueComments(iss *IssueParameter) ([]Comment, error)
+
+	// AssignIssue replaces the assignees of issue with iss.Reviewers
+	AssignIssue(iss *IssueParameter) error
+	AddIssueAssignees(iss *IssueParameter) error
+	RemoveIssueAssignees(iss *IssueParameter) error
+
+	GetIssue(iss *IssueParameter) (*Issue, error)
+	CloseIssue(iss *IssueParameter) error
+	ReopenIssue(iss *IssueParameter) error
+	SetIssueMilestone(iss *IssueParameter) error
+	SetIssueType(iss *IssueParameter) error
+
+	ListOpenIssues(org, repo string) ([]string, error)
+}
+
+const (
+	IssueStateOpen        = "open"
+	IssueStateProgressing = "progressing"
+	IssueStateClosed      = "closed"
+)
+
+// Issue is the details of an issue.
+type Issue struct {
+	Number string
+	Title  string
+	Body   string
+	Author string
+	State  string
+	// Assignees are the users the issue is assigned to, the first one is the
+	// principal assignee and the others are the collaborators on gitee
+	Assignees []string
+	Labels    []string
+	// Milestone is the number of milestone, it is empty if the issue has no milestone
+	Milestone      string
+	MilestoneTitle string
+	IssueType      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type ContentInfo struct {
@@ -110,6 +284,11 @@ type ContentInfo struct {
 	Content     *string `json:"content,omitempty"`
 }
 
+type StatusClient interface {
+	// Ping checks whether the platform api is reachable with the token.
+	Ping() error
+}
+
 type RepoClient interface {
 	GetRepoContentsByPath(path string) ([]*ContentInfo, error)
 	ListCollaborator(org, repo string) ([]string, error)
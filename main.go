@@ -2,20 +2,29 @@ package main
 
 import (
 	"community-robot-lib/framework"
+	"community-robot-lib/httprecord"
 	"community-robot-lib/logrusutil"
+	"community-robot-lib/metrics"
 	liboptions "community-robot-lib/options"
 	"community-robot-lib/secret"
+	"community-robot-lib/utils"
 	"flag"
+	"fmt"
 	sdk "git-platform-sdk"
 	sig "github.com/opensourceways/robot-sig-info-cache"
 	"github.com/sirupsen/logrus"
+	"io"
 	"net/url"
-	"time"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 type options struct {
 	service liboptions.ServiceOptions
 	client  liboptions.ClientOptions
+	relabel string
+	explain string
 }
 
 func (o *options) Validate() error {
@@ -37,6 +46,8 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	o.service.AddFlags(fs)
 	fs.StringVar(&o.client.CacheEndpoint, "cache-endpoint", "", "The endpoint of repo file cache")
 	fs.IntVar(&o.client.CacheMaxRetries, "max-retries", 3, "The number of failed retry attempts to call the cache api")
+	fs.StringVar(&o.relabel, "relabel", "", "The comma separated org/repo list whose open issues and PRs will be relabeled with the sig label, the robot exits when finished")
+	fs.StringVar(&o.explain, "config-explain", "", "The org/repo whose effective config is printed with where each value comes from, the robot exits when finished")
 
 	_ = fs.Parse(args)
 	return o
@@ -45,33 +56,147 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 func main() {
 	logrusutil.ComponentInit(botName)
 
-	//o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
-	o := options{
-		service: liboptions.ServiceOptions{
-			Port:        8833,
-			ConfigFile:  "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-openeuler-welcome\\local\\config.yaml",
-			GracePeriod: 300 * time.Second,
-		},
-		client: liboptions.ClientOptions{
-			TokenPath:       "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-openeuler-welcome\\local\\token",
-			RepoCacheDir:    "",
-			CacheRepoOnPV:   true,
-			CacheEndpoint:   "http://localhost:8888/v1/file",
-			CacheMaxRetries: 1,
-		},
-	}
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
+	if o.explain != "" {
+		if err := explainConfig(os.Stdout, o.service.ConfigFile, o.explain); err != nil {
+			logrus.WithError(err).Fatal("Error explaining config.")
+		}
+
+		return
+	}
+
+	secrets := []string{o.client.TokenPath}
+	if o.service.AdminTokenPath != "" {
+		secrets = append(secrets, o.service.AdminTokenPath)
+	}
+
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{o.client.TokenPath}); err != nil {
+	if err := secretAgent.Start(secrets); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
 	defer secretAgent.Stop()
 
-	p := newRobot(sdk.GetClientInstance(secretAgent.GetSecret(o.client.TokenPath)), sig.NewSDK(o.client.CacheEndpoint, o.client.CacheMaxRetries))
+	// the token of platform is not the secret of webhook, so it only tells the readiness
+	o.client.SecretsLoaded = func() bool {
+		return len(secretAgent.GetSecret(o.client.TokenPath)) != 0
+	}
+
+	if o.service.AdminTokenPath != "" {
+		o.service.AdminTokenGenerator = secretAgent.GetTokenGenerator(o.service.AdminTokenPath)
+	}
+
+	var sink *utils.JSONLines
+	if o.service.DryRunSink != "" {
+		var err error
+		if sink, err = utils.NewJSONLines(o.service.DryRunSink); err != nil {
+			logrus.WithError(err).Fatal("Error opening dry run sink.")
+		}
+
+		defer sink.Close()
+	}
+
+	sdk.SetAPICallObserver(metrics.ObservePlatformAPICall)
+	sdk.SetRateLimit(sdk.RateLimit{
+		QPS:        o.client.APIQPS,
+		Burst:      o.client.APIBurst,
+		MaxRetries: o.client.APIMaxRetries,
+	})
+
+	sigCli := sig.NewSDK(o.client.CacheEndpoint, o.client.CacheMaxRetries)
+
+	if o.client.RecordDir != "" {
+		v, stop, err := startRecording(o.client.RecordDir, secretAgent.Censor, sigCli)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error starting recording.")
+		}
+
+		defer stop()
+
+		sigCli = v
+	}
+
+	var cli sdk.Client = sdk.GetClientInstance(secretAgent.GetSecret(o.client.TokenPath))
+	dryRunCli := sdk.NewDryRunClient(cli, newDryRunRecorder(sink))
+	if o.service.DryRun {
+		cli = dryRunCli
+	}
+
+	p := newRobot(cli, dryRunCli, sigCli)
+
+	if o.relabel != "" {
+		if err := runRelabel(p, o.service.ConfigFile, strings.Split(o.relabel, ",")); err != nil {
+			logrus.WithError(err).Fatal("Error relabeling.")
+		}
+
+		return
+	}
 
 	framework.Run(p, o.service, o.client)
 }
+
+// startRecording records the requests to the platform and sig-info-cache with the responses
+// to the fixtures in dir. It returns the sig-info-cache client which records the requests.
+func startRecording(dir string, censor httprecord.Censor, sigCli *sig.SDK) (*sig.SDK, func(), error) {
+	platformRec, err := httprecord.NewRecorder(filepath.Join(dir, "platform.jsonl"), censor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sigRec, err := httprecord.NewRecorder(filepath.Join(dir, "sig-info.jsonl"), censor)
+	if err != nil {
+		_ = platformRec.Close()
+
+		return nil, nil, err
+	}
+
+	sdk.WrapTransport(platformRec.Transport)
+
+	stop := func() {
+		_ = platformRec.Close()
+		_ = sigRec.Close()
+	}
+
+	return sigCli.WithTransport(sigRec.Transport(nil)), stop, nil
+}
+
+func runRelabel(bot *robot, configFile string, repos []string) error {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	return bot.relabelOpenItems(cfg, repos, logrus.WithField("function", "relabel"))
+}
+
+func explainConfig(w io.Writer, configFile, orgRepo string) error {
+	org, repo, ok := strings.Cut(orgRepo, "/")
+	if !ok || org == "" || repo == "" {
+		return fmt.Errorf("invalid repo: %s", orgRepo)
+	}
+
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	return cfg.explain(w, org, repo)
+}
+
+func loadConfig(configFile string) (*configuration, error) {
+	cfg := &configuration{}
+	if err := utils.LoadFromYaml(configFile, cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.SetDefault()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	sdk "git-platform-sdk"
+)
+
+const newcomerLabel = "newcomer"
+
+// ownLabels are the labels added by the robot, they are kept when pruning the repo labels.
+var ownLabels = []string{newcomerLabel}
+
+// syncRepoLabels makes the repo have the labels of config, the sig label and ownLabels are always kept.
+// It is done once for each repo unless the config changes or it fails.
+func (bot *robot) syncRepoLabels(p *eventArgs, sigLabel string) {
+	cfg := p.cnf
+	if len(cfg.RepoLabels) == 0 {
+		return
+	}
+
+	desired := append([]sdk.Label(nil), cfg.RepoLabels...)
+	for _, name := range append([]string{sigLabel}, ownLabels...) {
+		if !hasLabel(desired, name) {
+			desired = append(desired, sdk.Label{Name: name})
+		}
+	}
+
+	key := p.event.Org + "/" + p.event.Repo
+	fingerprint := fmt.Sprintf("%v %t", desired, cfg.PruneRepoLabels)
+
+	if v, ok := bot.syncedLabels.Load(key); ok && v == fingerprint {
+		return
+	}
+
+	changes, err := sdk.SyncRepoLabels(p.cli, p.event.Org, p.event.Repo, desired, cfg.PruneRepoLabels)
+	if !changes.IsEmpty() {
+		p.log.Infof(
+			"sync repo labels, created: %v, updated: %v, renamed: %v, deleted: %v",
+			changes.Created, changes.Updated, changes.Renamed, changes.Deleted,
+		)
+	}
+
+	if err != nil {
+		p.log.Errorf("sync repo labels, err:%s", err.Error())
+
+		return
+	}
+
+	bot.syncedLabels.Store(key, fingerprint)
+}
+
+// hasLabel reports whether name is one of the labels or their aliases.
+func hasLabel(ls []sdk.Label, name string) bool {
+	for i := range ls {
+		if ls[i].Name == name {
+			return true
+		}
+
+		for _, alias := range ls[i].Aliases {
+			if alias == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"community-robot-lib/config"
 	"community-robot-lib/framework"
 	"community-robot-lib/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	sdk "git-platform-sdk"
@@ -11,7 +12,10 @@ import (
 	"github.com/sirupsen/logrus"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -31,35 +35,114 @@ If you have any questions, please contact the SIG: [%s](https://gitee.com/openeu
 `
 )
 
+// defaultPullsEndpoint counts the PRs of author in the community.
+const defaultPullsEndpoint = "https://ipb.osinfra.cn/pulls"
+
+// sigInfoClient is the client of sig-info-cache.
+type sigInfoClient interface {
+	GetSigNameByOrgRepo(org, repo string) (string, error)
+	GetSigName(name string) (string, error)
+	GetRepositoryMaintainerByOrgRepo(org, repo string) ([]string, error)
+	GetRepositoryCommitterByOrgRepo(org, repo string) ([]string, error)
+	GetContentByPath(path string) (*map[string]any, error)
+	Ping() error
+}
+
 type robot struct {
-	cli    *sdk.ClientTarget
-	sigCli *sig.SDK
+	cli       sdk.Client
+	dryRunCli sdk.Client
+	sigCli    sigInfoClient
+
+	// syncedLabels records the repo labels synchronized, it maps org/repo to the desired labels
+	syncedLabels sync.Map
+
+	// pullsEndpoint is the api which counts the PRs of author, it tells whether the author is a newcomer
+	pullsEndpoint string
+	pullsCli      *http.Client
+}
+
+func newRobot(cli, dryRunCli sdk.Client, sigCli sigInfoClient) *robot {
+	return &robot{
+		cli:           cli,
+		dryRunCli:     dryRunCli,
+		sigCli:        sigCli,
+		pullsEndpoint: defaultPullsEndpoint,
+		pullsCli:      http.DefaultClient,
+	}
+}
+
+// sigClient returns the client of sig-info-cache whose requests are made with ctx.
+func (bot *robot) sigClient(ctx context.Context) sigInfoClient {
+	if v, ok := bot.sigCli.(*sig.SDK); ok {
+		return v.WithContext(ctx)
+	}
+
+	return bot.sigCli
 }
 
-func newRobot(cli *sdk.ClientTarget, sigSdk *sig.SDK) *robot {
-	return &robot{cli: cli, sigCli: sigSdk}
+// clientFor returns the client which only records the write calls if the config is in dry run mode.
+func (bot *robot) clientFor(cfg *botConfig) sdk.Client {
+	if cfg != nil && cfg.DryRun {
+		return bot.dryRunCli
+	}
+
+	return bot.cli
 }
 
 func (bot *robot) NewConfig() config.Config {
 	return &configuration{}
 }
 
+// OnConfigChange forgets the repo labels synchronized, so that they are synchronized again
+// with the new config, in case they are changed on the platform meanwhile.
+func (bot *robot) OnConfigChange(md5Sum string, _ config.Config) {
+	bot.syncedLabels.Range(func(k, _ any) bool {
+		bot.syncedLabels.Delete(k)
+
+		return true
+	})
+
+	logrus.WithField("md5", md5Sum).Info("config changed")
+}
+
 func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, error) {
 	c, ok := cfg.(*configuration)
 	if !ok {
 		return nil, fmt.Errorf("can't convert to configuration")
 	}
 
-	if bc := c.configFor(org, repo); bc != nil {
-		return bc, nil
+	bc, err := c.configFor(org, repo)
+	if err != nil || bc != nil {
+		return bc, err
 	}
 
 	return nil, fmt.Errorf("no config for this repo:%s/%s", org, repo)
 }
 
 func (bot *robot) RegisterEventHandler(f framework.HandlerRegister) {
-	f.RegisterIssueHandler(bot.handleIssue)
-	f.RegisterPullRequestHandler(bot.handlePullRequest)
+	f.RegisterContextHandler(framework.IssueEvent, bot.handleIssue)
+	f.RegisterContextHandler(framework.PullRequestEvent, bot.handlePullRequest)
+
+	cmds := framework.NewCommands(bot.hasPermission)
+	cmds.Register("sig", bot.handleSigCommand, true)
+	cmds.Register("assign", bot.handleAssignCommand, true)
+	cmds.Register("welcome", bot.handleWelcomeCommand, false)
+
+	f.RegisterIssueCommentHandler(cmds.Handle)
+	f.RegisterReviewCommentEventHandler(cmds.Handle)
+}
+
+// probeInterval is the interval to probe the dependencies of robot
+const probeInterval = 30 * time.Second
+
+var (
+	_ framework.HealthChecker    = (*robot)(nil)
+	_ framework.ConfigSubscriber = (*robot)(nil)
+)
+
+func (bot *robot) RegisterHealthChecks(f framework.HealthCheckRegister) {
+	f.RegisterReadinessCheck("sig-info-cache", bot.sigCli.Ping, probeInterval)
+	f.RegisterReadinessCheck("platform-api", bot.cli.Ping, probeInterval)
 }
 
 const (
@@ -68,17 +151,24 @@ const (
 )
 
 type eventArgs struct {
+	cli     sdk.Client
+	sigCli  sigInfoClient
 	event   *sdk.GenericEvent
 	cnf     *botConfig
 	log     *logrus.Entry
 	flag    int
 	author  string
 	sigName string
+	// action is the normalized action of event
+	action string
+	// maintainers is cached since both welcome and assignment need it
+	maintainers []string
 }
 
-func (bot *robot) handlePullRequest(e *sdk.GenericEvent, pc config.Config, log *logrus.Entry) error {
-	if e.Action != sdk.ActionStateCreated {
-		return nil
+func (bot *robot) handlePullRequest(ctx context.Context, e *sdk.GenericEvent, pc config.Config, log *logrus.Entry) error {
+	// the fields set by the converter of webhook are used if the payload is malformed
+	if err := e.ParsePayload(); err != nil {
+		log.WithError(err).Warn("parse the payload")
 	}
 
 	cfg, err := bot.getConfig(pc, e.Org, e.Repo)
@@ -86,21 +176,37 @@ func (bot *robot) handlePullRequest(e *sdk.GenericEvent, pc config.Config, log *
 		return err
 	}
 
+	action := sdk.NormalizeAction(e.Action)
+	if !cfg.handles(action) {
+		return nil
+	}
+
+	if reason := cfg.Filter.skipReason(newFilterTarget(e, true)); reason != "" {
+		log.Infof("skip the pr, since %s", reason)
+		return nil
+	}
+
 	p := &eventArgs{
+		cli:    bot.clientFor(cfg).WithContext(ctx),
+		sigCli: bot.sigClient(ctx),
 		flag:   PullRequest,
 		event:  e,
 		author: e.PRAuthor,
 		cnf:    cfg,
 		log:    log,
+		action: action,
 	}
 
-	bot.handleNewcomerLabel(p)
+	if triggers(cfg.WelcomeActions, action) {
+		bot.handleNewcomerLabel(ctx, p)
+	}
 	return bot.handle(p)
 }
 
-func (bot *robot) handleIssue(e *sdk.GenericEvent, pc config.Config, log *logrus.Entry) error {
-	if e.Action != sdk.ActionStateCreated {
-		return nil
+func (bot *robot) handleIssue(ctx context.Context, e *sdk.GenericEvent, pc config.Config, log *logrus.Entry) error {
+	// the fields set by the converter of webhook are used if the payload is malformed
+	if err := e.ParsePayload(); err != nil {
+		log.WithError(err).Warn("parse the payload")
 	}
 
 	cfg, err := bot.getConfig(pc, e.Org, e.Repo)
@@ -108,22 +214,44 @@ func (bot *robot) handleIssue(e *sdk.GenericEvent, pc config.Config, log *logrus
 		return err
 	}
 
+	action := sdk.NormalizeAction(e.Action)
+	if !cfg.handles(action) {
+		return nil
+	}
+
+	if reason := cfg.Filter.skipReason(newFilterTarget(e, false)); reason != "" {
+		log.Infof("skip the issue, since %s", reason)
+		return nil
+	}
+
 	p := &eventArgs{
+		cli:    bot.clientFor(cfg).WithContext(ctx),
+		sigCli: bot.sigClient(ctx),
 		flag:   Issue,
 		event:  e,
 		author: e.IssueAuthor,
 		cnf:    cfg,
 		log:    log,
+		action: action,
 	}
 
 	return bot.handle(p)
 }
 
-func (bot *robot) handleNewcomerLabel(p *eventArgs) {
+func (bot *robot) handleNewcomerLabel(ctx context.Context, p *eventArgs) {
 	mErr := utils.NewMultiErrors()
-	resp, err := http.Get(fmt.Sprintf("https://ipb.osinfra.cn/pulls?author=%s", p.author))
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, bot.pullsEndpoint+"?"+url.Values{"author": {p.author}}.Encode(), nil,
+	)
 	if err != nil {
-		mErr.AddError(err)
+		p.log.Error(err)
+		return
+	}
+
+	resp, err := bot.pullsCli.Do(req)
+	if err != nil {
+		p.log.Error(err)
+		return
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
@@ -140,11 +268,11 @@ func (bot *robot) handleNewcomerLabel(p *eventArgs) {
 	}
 
 	if t.Total == 0 {
-		if err = bot.cli.AddPRLabels(&sdk.PRParameter{
+		if err = p.cli.AddPRLabels(&sdk.PRParameter{
 			Org:    p.event.Org,
 			Repo:   p.event.Repo,
 			Number: p.event.PRNumber,
-			Labels: []string{"newcomer"},
+			Labels: []string{newcomerLabel},
 		}); err != nil {
 			mErr.AddError(err)
 		}
@@ -157,107 +285,147 @@ func (bot *robot) handleNewcomerLabel(p *eventArgs) {
 
 func (bot *robot) handle(p *eventArgs) error {
 
-	sigName, err := bot.sigCli.GetSigNameByOrgRepo(p.event.Org, p.event.Repo)
+	sigName, err := p.sigCli.GetSigNameByOrgRepo(p.event.Org, p.event.Repo)
 
 	if err != nil {
 		return err
 	}
 
 	p.sigName = sigName
-	comment, err := bot.generateComment(p)
 
+	cfg := p.cnf
 	mErr := utils.NewMultiErrors()
-	if p.flag == Issue {
-		err = bot.cli.AddIssueComment(&sdk.IssueParameter{
-			Org:     p.event.Org,
-			Repo:    p.event.Repo,
-			Number:  p.event.IssueNumber,
-			Comment: comment,
-		})
-	} else {
-		err = bot.cli.AddPRComment(&sdk.PRParameter{
-			Org:     p.event.Org,
-			Repo:    p.event.Repo,
-			Number:  p.event.PRNumber,
-			Comment: comment,
-		})
+
+	if cfg.NeedAssign && triggers(cfg.ReassignActions, p.action) {
+		mErr.AddError(bot.assign(p))
 	}
-	if err != nil {
-		mErr.AddError(err)
+
+	if triggers(cfg.WelcomeActions, p.action) {
+		mErr.AddError(bot.welcome(p))
 	}
 
-	label := fmt.Sprintf("sig/%s", sigName)
-	if n := 20; len(label) > n {
-		label = label[:n]
+	if triggers(cfg.RelabelActions, p.action) {
+		mErr.AddError(bot.relabel(p))
 	}
 
-	if err = bot.createLabelIfNeed(p.event.Org, p.event.Repo, label); err != nil {
+	return mErr.Err()
+}
+
+// relabel makes the issue or PR have the label of sig which the repo belongs to now.
+func (bot *robot) relabel(p *eventArgs) error {
+	if p.sigName == "" {
+		p.log.Warn("skip the sig label, since the repo belongs to no sig")
+
+		return nil
+	}
+
+	label := sigLabel(p.sigName)
+
+	bot.syncRepoLabels(p, label)
+
+	if err := bot.createLabelIfNeed(p.cli, p.event.Org, p.event.Repo, label); err != nil {
 		p.log.Errorf("create repo label:%s, err:%s", label, err.Error())
 	}
 
+	// the labels of other sigs are stale if the issue is transferred from the repo of other sig
+	removeStale := p.cnf.RemoveStaleSigLabel || p.action == sdk.ActionTransferred
+
+	return bot.syncSigLabel(p, label, removeStale)
+}
+
+// assign assigns the maintainers to the PR or issue.
+func (bot *robot) assign(p *eventArgs) error {
+	maintainers, err := bot.maintainersOf(p)
+	if err != nil || len(maintainers) == 0 {
+		return err
+	}
+
 	if p.flag == Issue {
-		err = bot.cli.AddIssueLabels(&sdk.IssueParameter{
-			Org:    p.event.Org,
-			Repo:   p.event.Repo,
-			Number: p.event.IssueNumber,
-			Labels: []string{label},
-		})
-	} else {
-		err = bot.cli.AddPRLabels(&sdk.PRParameter{
-			Org:    p.event.Org,
-			Repo:   p.event.Repo,
-			Number: p.event.PRNumber,
-			Labels: []string{label},
+		return p.cli.AssignIssue(&sdk.IssueParameter{
+			Org:       p.event.Org,
+			Repo:      p.event.Repo,
+			Number:    p.event.IssueNumber,
+			Reviewers: maintainers,
 		})
 	}
+
+	return p.cli.AssignPR(&sdk.PRParameter{
+		Org:       p.event.Org,
+		Repo:      p.event.Repo,
+		Number:    p.event.PRNumber,
+		Reviewers: maintainers,
+	})
+}
+
+// welcome posts the welcome message to the issue or PR. p.sigName must be set.
+func (bot *robot) welcome(p *eventArgs) error {
+	comment, err := bot.generateComment(p)
 	if err != nil {
-		mErr.AddError(err)
+		return err
 	}
 
-	return mErr.Err()
-}
+	if p.flag == Issue {
+		return p.cli.AddIssueComment(&sdk.IssueParameter{
+			Org:     p.event.Org,
+			Repo:    p.event.Repo,
+			Number:  p.event.IssueNumber,
+			Comment: comment,
+		})
+	}
 
-func (bot *robot) generateComment(p *eventArgs) (string, error) {
+	return p.cli.AddPRComment(&sdk.PRParameter{
+		Org:     p.event.Org,
+		Repo:    p.event.Repo,
+		Number:  p.event.PRNumber,
+		Comment: comment,
+	})
+}
 
-	if p.cnf.NoNeedToNotice {
-		return fmt.Sprintf(welcomeMessage3, p.author, p.cnf.CommunityName, p.cnf.CommandLink, p.sigName, p.sigName), nil
+// maintainersOf returns the collaborators of repo and the maintainers of sig or the owners of changed files.
+func (bot *robot) maintainersOf(p *eventArgs) ([]string, error) {
+	if p.maintainers != nil {
+		return p.maintainers, nil
 	}
 
-	var maintainers []string
-	maintainersFromGitPlatform, err := bot.cli.ListCollaborator(p.event.Org, p.event.Repo)
+	maintainers := []string{}
+	maintainersFromGitPlatform, err := p.cli.ListCollaborator(p.event.Org, p.event.Repo)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 仓库自己配置 maintainers - 仓库下不同目录归属不同的 owner
 	if p.cnf.WelcomeSimpler {
-		contentMap, err1 := bot.sigCli.GetContentByPath("")
+		contentMap, err1 := p.sigCli.GetContentByPath("")
 		repoOwner := matchOwnerByPRChanges(contentMap, p)
 		if err1 == nil && len(repoOwner) != 0 {
 			maintainers = append(maintainersFromGitPlatform, repoOwner...)
 		}
 	} else {
 
-		maintainersFromSigInfo, err2 := bot.sigCli.GetRepositoryMaintainerByOrgRepo(p.event.Org, p.event.Repo)
+		maintainersFromSigInfo, err2 := p.sigCli.GetRepositoryMaintainerByOrgRepo(p.event.Org, p.event.Repo)
 		if err2 != nil {
-			return "", err2
+			return nil, err2
 		}
 		maintainers = append(maintainersFromGitPlatform, maintainersFromSigInfo...)
 	}
 
-	if p.cnf.NeedAssign {
-		// missing assign issue
-		if err = bot.cli.AssignPR(&sdk.PRParameter{
-			Org:       p.event.Org,
-			Repo:      p.event.Repo,
-			Number:    p.event.PRNumber,
-			Reviewers: maintainers,
-		}); err != nil {
-			return "", err
-		}
+	p.maintainers = maintainers
+
+	return maintainers, nil
+}
+
+func (bot *robot) generateComment(p *eventArgs) (string, error) {
+
+	if p.cnf.NoNeedToNotice {
+		return fmt.Sprintf(welcomeMessage3, p.author, p.cnf.CommunityName, p.cnf.CommandLink, p.sigName, p.sigName), nil
 	}
 
-	committers, _ := bot.sigCli.GetRepositoryCommitterByOrgRepo(p.event.Org, p.event.Repo)
+	maintainers, err := bot.maintainersOf(p)
+	if err != nil {
+		return "", err
+	}
+
+	committers, _ := p.sigCli.GetRepositoryCommitterByOrgRepo(p.event.Org, p.event.Repo)
 	if len(committers) != 0 {
 		return fmt.Sprintf(
 			welcomeMessage2, p.author, p.cnf.CommunityName, p.cnf.CommandLink,
@@ -271,13 +439,13 @@ func (bot *robot) generateComment(p *eventArgs) (string, error) {
 	), nil
 }
 
-func (bot *robot) createLabelIfNeed(org, repo, label string) error {
+func (bot *robot) createLabelIfNeed(cli sdk.Client, org, repo, label string) error {
 	arg := &sdk.LabelParameter{
 		Org:  org,
 		Repo: repo,
 		Name: label,
 	}
-	repoLabels, err := bot.cli.GetRepoLabels(arg)
+	repoLabels, err := cli.GetRepoLabels(arg)
 	if err != nil {
 		return err
 	}
@@ -286,5 +454,10 @@ func (bot *robot) createLabelIfNeed(org, repo, label string) error {
 		return nil
 	}
 
-	return bot.cli.AddRepoLabels(arg)
+	// the label may be created by the other event at the same time
+	if err := cli.AddRepoLabels(arg); err != nil && !sdk.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"community-robot-lib/framework"
+	sdk "git-platform-sdk"
+	"git-platform-sdk/fake"
+)
+
+const (
+	testConfig = "testdata/config.yaml"
+	testSig    = "Compiler"
+)
+
+func newTestHarness(t *testing.T) (*framework.Harness, *fake.Platform, *robot) {
+	t.Helper()
+
+	sigInfo := fake.NewSigInfo()
+	sigInfo.AddSig("Kernel")
+	for _, org := range []string{"org", "dry", "filtered", "pruned"} {
+		sigInfo.AddRepo(org, "repo", fake.SigRepo{
+			Sig:         testSig,
+			Maintainers: []string{"maintainer"},
+			Committers:  []string{"committer"},
+		})
+	}
+
+	platform := fake.New()
+	bot := newRobot(
+		platform,
+		sdk.NewDryRunClient(platform, func(string, any) {}),
+		sigInfo,
+	)
+
+	// nobody has PRs in the community, so the authors are newcomers
+	pulls := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total":0}`))
+	}))
+	t.Cleanup(pulls.Close)
+
+	bot.pullsEndpoint = pulls.URL
+	bot.pullsCli = pulls.Client()
+
+	h, err := framework.NewHarness(bot, testConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Cleanup(h.Close)
+
+	return h, platform, bot
+}
+
+func issuePayload(org, repo, number, author string) []byte {
+	return issueActionPayload("created", org, repo, number, author)
+}
+
+func issueActionPayload(action, org, repo, number, author string) []byte {
+	return []byte(fmt.Sprintf(
+		`{"action":"%s","repository":{"full_name":"%s/%s"},"issue":{"number":"%s","user":{"login":"%s"}}}`,
+		action, org, repo, number, author,
+	))
+}
+
+func commentPayload(org, repo, kind, number, commenter, comment string) []byte {
+	return []byte(fmt.Sprintf(
+		`{"action":"created","repository":{"full_name":"%s/%s"},"%s":{"number":"%s","user":{"login":"author"}},"comment":{"body":%q,"user":{"login":"%s"}}}`,
+		org, repo, kind, number, comment, commenter,
+	))
+}
+
+func TestIssueCreated(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	platform.AddIssue("org", "repo", "I1", "newbie")
+
+	if err := h.Send("Issue Hook", issuePayload("org", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	issue := platform.Issue("org", "repo", "I1")
+
+	if len(issue.Comments) != 1 {
+		t.Fatalf("Expected 1 comment, got %d", len(issue.Comments))
+	}
+
+	if c := issue.Comments[0].Body; !strings.Contains(c, "newbie") || !strings.Contains(c, "openEuler") {
+		t.Errorf("Unexpected welcome message: %s", c)
+	}
+
+	label := sigLabel(testSig)
+
+	if !platform.Repo("org", "repo").Labels.Has(label) {
+		t.Errorf("Expected the repo label %s to be created", label)
+	}
+
+	if !issue.Labels.Has(label) {
+		t.Errorf("Expected the issue to be labeled with %s, got %v", label, issue.Labels.List())
+	}
+}
+
+func TestIssueCreatedInDryRun(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	platform.AddIssue("dry", "repo", "I1", "newbie")
+
+	if err := h.Send("Issue Hook", issuePayload("dry", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	issue := platform.Issue("dry", "repo", "I1")
+	if len(issue.Comments) != 0 || issue.Labels.Len() != 0 {
+		t.Errorf("Expected no write in dry run, got comments: %v, labels: %v", issue.Comments, issue.Labels.List())
+	}
+}
+
+func TestIssueCreatedWithFault(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	platform.AddIssue("org", "repo", "I1", "newbie")
+
+	fault := errors.New("internal server error")
+	platform.InjectFault("AddIssueComment", 1, fault)
+
+	err := h.Send("Issue Hook", issuePayload("org", "repo", "I1", "newbie"))
+	if err == nil || !strings.Contains(err.Error(), fault.Error()) {
+		t.Fatalf("Expected the injected fault, got %v", err)
+	}
+
+	// the label is still synchronized
+	if issue := platform.Issue("org", "repo", "I1"); issue.Labels.Len() != 1 {
+		t.Errorf("Expected the sig label, got %v", issue.Labels.List())
+	}
+
+	if err = h.Send("Issue Hook", issuePayload("org", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error after the fault: %v", err)
+	}
+}
+
+func TestIssueCreatedWhenLabelExists(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	platform.AddIssue("org", "repo", "I1", "newbie")
+
+	// the label is created by the other event after it is checked
+	platform.InjectFault("AddRepoLabels", 1, &sdk.Error{
+		Operation:  "create a repo label",
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "label already exists",
+	})
+
+	if err := h.Send("Issue Hook", issuePayload("org", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if issue := platform.Issue("org", "repo", "I1"); issue.Labels.Len() != 1 || len(issue.Comments) != 1 {
+		t.Errorf("Expected the issue is labeled and welcomed, got %v", issue)
+	}
+}
+
+func TestIssueActions(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	issue := platform.AddIssue("org", "repo", "I1", "newbie")
+	issue.Labels.Insert("sig/Other")
+
+	// it is ignored since edited triggers nothing by default
+	if err := h.Send("Issue Hook", issueActionPayload("edit", "org", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if calls := platform.Calls(); len(calls) != 0 {
+		t.Fatalf("Expected no call for the edited issue, got %v", calls)
+	}
+
+	if err := h.Send("Issue Hook", issueActionPayload("transferred", "org", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(issue.Comments) != 0 {
+		t.Errorf("Expected no welcome for the transferred issue, got %v", issue.Comments)
+	}
+
+	if v := issue.Labels.List(); len(v) != 1 || v[0] != sigLabel(testSig) {
+		t.Errorf("Expected only the label of current sig, got %v", v)
+	}
+}
+
+func TestIssueOfRepoWithoutSig(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	issue := platform.AddIssue("org", "nosig", "I1", "newbie")
+	issue.Labels.Insert("sig/Other")
+
+	if err := h.Send("Issue Hook", issuePayload("org", "nosig", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if v := issue.Labels.List(); len(v) != 1 || v[0] != "sig/Other" {
+		t.Errorf("Expected the sig labels are kept, got %v", v)
+	}
+
+	if len(issue.Comments) != 1 {
+		t.Errorf("Expected 1 comment, got %d", len(issue.Comments))
+	}
+}
+
+func TestPruneRepoLabels(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+	platform.AddIssue("pruned", "repo", "I1", "newbie")
+	platform.Repo("pruned", "repo").Labels.Insert(newcomerLabel, "stale")
+
+	if err := h.Send("Issue Hook", issuePayload("pruned", "repo", "I1", "newbie")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expect := []string{"bug", newcomerLabel, sigLabel(testSig)}
+	if v := platform.Repo("pruned", "repo").Labels.List(); !reflect.DeepEqual(v, expect) {
+		t.Errorf("Expected the labels %v, got %v", expect, v)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	h, platform, _ := newTestHarness(t)
+
+	cases := []struct {
+		name    string
+		payload string
+		handled bool
+	}{
+		{"bot", `"user":{"login":"dependabot[bot]","type":"Bot"},"base":{"ref":"master"}`, false},
+		{"author", `"user":{"login":"openeuler-ci"},"base":{"ref":"master"}`, false},
+		{"draft", `"user":{"login":"newbie"},"draft":true,"base":{"ref":"master"}`, false},
+		{"title", `"user":{"login":"newbie"},"title":"build(deps): bump x","base":{"ref":"master"}`, false},
+		{"branch", `"user":{"login":"newbie"},"base":{"ref":"feature"}`, false},
+		{"handled", `"user":{"login":"newbie"},"base":{"ref":"openEuler-24.03-LTS"}`, true},
+	}
+
+	for i, c := range cases {
+		number := fmt.Sprint(i + 1)
+		pr := platform.AddPR("filtered", "repo", number, "newbie")
+		payload := fmt.Sprintf(
+			`{"action":"opened","repository":{"full_name":"filtered/repo"},"pull_request":{"number":%s,%s}}`,
+			number, c.payload,
+		)
+
+		if err := h.Send("Merge Request Hook", []byte(payload)); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+
+		if handled := len(pr.Comments) != 0; handled != c.handled {
+			t.Errorf("%s: expected handled %t, got %t", c.name, c.handled, handled)
+		}
+
+		if newcomer := pr.Labels.Has(newcomerLabel); newcomer != c.handled {
+			t.Errorf("%s: expected the newcomer label %t, got %t", c.name, c.handled, newcomer)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	testCases := []struct {
+		description string
+		kind        string
+		commenter   string
+		comment     string
+		labels      []string
+		assignees   []string
+	}{
+		{
+			description: "collaborator changes the sig of issue",
+			kind:        "issue",
+			commenter:   "alice",
+			comment:     "/sig kernel",
+			labels:      []string{"sig/Kernel"},
+		},
+		{
+			description: "collaborator can't apply the label of unknown sig",
+			kind:        "issue",
+			commenter:   "alice",
+			comment:     "/sig kernal",
+		},
+		{
+			description: "stranger can't change the sig of issue",
+			kind:        "issue",
+			commenter:   "mallory",
+			comment:     "/sig kernel",
+		},
+		{
+			description: "collaborator assigns the pr",
+			kind:        "pull_request",
+			commenter:   "Alice",
+			comment:     "/assign @bob",
+			assignees:   []string{"bob"},
+		},
+		{
+			description: "collaborator assigns the issue to self",
+			kind:        "issue",
+			commenter:   "alice",
+			comment:     "thanks\n/assign",
+			assignees:   []string{"alice"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			h, platform, _ := newTestHarness(t)
+			platform.AddRepo("org", "repo", "alice")
+
+			item := platform.AddIssue("org", "repo", "1", "author")
+			if tc.kind == "pull_request" {
+				item = platform.AddPR("org", "repo", "1", "author")
+			}
+
+			payload := commentPayload("org", "repo", tc.kind, "1", tc.commenter, tc.comment)
+			if err := h.Send("Note Hook", payload); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if v := item.Labels.List(); len(v) != len(tc.labels) || (len(v) > 0 && v[0] != tc.labels[0]) {
+				t.Errorf("Expected labels %v, got %v", tc.labels, v)
+			}
+
+			if v := item.Assignees.List(); len(v) != len(tc.assignees) || (len(v) > 0 && v[0] != tc.assignees[0]) {
+				t.Errorf("Expected assignees %v, got %v", tc.assignees, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sigsdk
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"community-robot-lib/httprecord"
+	"community-robot-lib/metrics"
+)
+
+func lookups(operation, result string) float64 {
+	return testutil.ToFloat64(metrics.SigInfoLookups.WithLabelValues(operation, result))
+}
+
+func TestSigInfoContract(t *testing.T) {
+	rp, err := httprecord.NewReplayer("testdata/sig_info.jsonl", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cli := NewSDK("http://sig-info-cache.example.com/v1/file", 1).WithTransport(rp)
+
+	hits, misses, errs := lookups("sig_name", "hit"), lookups("sig_name", "miss"), lookups("sig_info", "error")
+
+	name, err := cli.GetSigInfo("sig?org=openeuler&repo=kernel")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if name != "Kernel" {
+		t.Errorf("Expected sig Kernel, got %s", name)
+	}
+
+	if _, err = cli.GetSigInfo("sig?org=openeuler&repo=none"); err == nil {
+		t.Errorf("Expected error for the repo which doesn't exist")
+	}
+
+	if name, err = cli.GetSigName("kernel"); err != nil || name != "Kernel" {
+		t.Errorf("Expected sig Kernel, got %s, %v", name, err)
+	}
+
+	if name, err = cli.GetSigName("kernal"); err != nil || name != "" {
+		t.Errorf("Expected no such sig, got %s, %v", name, err)
+	}
+
+	if v := lookups("sig_name", "hit") - hits; v != 1 {
+		t.Errorf("Expected 1 hit of sig name, got %v", v)
+	}
+
+	if v := lookups("sig_name", "miss") - misses; v != 1 {
+		t.Errorf("Expected 1 miss of sig name, got %v", v)
+	}
+
+	if v := lookups("sig_info", "error") - errs; v != 1 {
+		t.Errorf("Expected 1 error of sig info, got %v", v)
+	}
+
+	// the endpoint is reachable even if it responds 404
+	if err = cli.Ping(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err = rp.Err(); err != nil {
+		t.Error(err)
+	}
+
+	if v := rp.Unused(); len(v) != 0 {
+		t.Errorf("Expected all the fixtures are used, unused: %v", v)
+	}
+}
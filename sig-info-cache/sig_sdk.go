@@ -1,9 +1,12 @@
 package sigsdk
 
 import (
+	"community-robot-lib/metrics"
 	"community-robot-lib/utils"
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -22,9 +25,36 @@ func NewSDK(endpoint string, maxRetries int) *SDK {
 type SDK struct {
 	hc       utils.HttpClient
 	endpoint string
+	ctx      context.Context
 }
 
-func (cli *SDK) GetSigNameByOrgRepo(org, repo string) (string, error) {
+// WithContext returns a copy of SDK whose requests are made with ctx.
+func (cli *SDK) WithContext(ctx context.Context) *SDK {
+	v := *cli
+	v.ctx = ctx
+
+	return &v
+}
+
+// WithTransport returns a copy of SDK whose requests are sent by rt, such as a recorder of the requests.
+func (cli *SDK) WithTransport(rt http.RoundTripper) *SDK {
+	v := *cli
+	v.hc.Client = &http.Client{Transport: rt}
+
+	return &v
+}
+
+func (cli *SDK) context() context.Context {
+	if cli.ctx != nil {
+		return cli.ctx
+	}
+
+	return context.Background()
+}
+
+func (cli *SDK) GetSigNameByOrgRepo(org, repo string) (name string, err error) {
+	defer func() { observeLookup("sig_name_by_repo", name, err) }()
+
 	sigName := "111"
 	if sigName == "" {
 		return "", fmt.Errorf("cant get sig name of repo: %s/%s", org, repo)
@@ -56,9 +86,31 @@ func (cli *SDK) GetRepositoryCommitterByOrgRepo(org, repo string) ([]string, err
 	return committers, nil
 }
 
-func (cli *SDK) GetSigInfo(urlPath string) (string, error) {
+// Ping checks whether the endpoint of sig-info-cache is reachable.
+func (cli *SDK) Ping() error {
+	req, err := http.NewRequestWithContext(cli.context(), http.MethodGet, cli.endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.hc.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("sig-info-cache responds with status:%s", resp.Status)
+	}
+
+	return nil
+}
+
+func (cli *SDK) GetSigInfo(urlPath string) (name string, err error) {
+	defer func() { observeLookup("sig_info", name, err) }()
 
-	req, err := http.NewRequest(http.MethodGet, cli.endpoint+urlPath, nil)
+	req, err := http.NewRequestWithContext(cli.context(), http.MethodGet, cli.endpoint+urlPath, nil)
 	if err != nil {
 		return "", err
 	}
@@ -67,21 +119,56 @@ func (cli *SDK) GetSigInfo(urlPath string) (string, error) {
 		Data map[string]string `json:"data"`
 	}
 
-	if err = cli.forwardTo(req, &v); err != nil {
+	if _, err = cli.forwardTo(req, &v); err != nil {
 		return "", err
 	}
 
 	return v.Data["111"], nil
 }
 
-func (cli *SDK) forwardTo(req *http.Request, jsonResp interface{}) error {
+// GetSigName returns the name of sig which equals name case-insensitively, it is empty if no such sig.
+func (cli *SDK) GetSigName(name string) (sig string, err error) {
+	defer func() { observeLookup("sig_name", sig, err) }()
+
+	req, err := http.NewRequestWithContext(
+		cli.context(), http.MethodGet, cli.endpoint+"sigs/"+url.PathEscape(name), nil,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	var v struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+
+	code, err := cli.forwardTo(req, &v)
+	if code == http.StatusNotFound {
+		return "", nil
+	}
+
+	return v.Data.Name, err
+}
+
+// observeLookup records the result of a lookup of sig-info cache, it is a miss if nothing is found.
+func observeLookup(operation, found string, err error) {
+	result := "hit"
+	if err != nil {
+		result = "error"
+	} else if found == "" {
+		result = "miss"
+	}
+
+	metrics.SigInfoLookups.WithLabelValues(operation, result).Inc()
+}
+
+func (cli *SDK) forwardTo(req *http.Request, jsonResp interface{}) (int, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "sig-info-cache-sdk")
 
-	_, err := cli.hc.ForwardTo(req, jsonResp)
-
-	return err
+	return cli.hc.ForwardTo(req, jsonResp)
 }
 
 func (cli *SDK) GetContentByPath(path string) (*map[string]any, error) {
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"community-robot-lib/utils"
+	sdk "git-platform-sdk"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const sigLabelPrefix = "sig/"
+
+// sigLabel returns the label which marks the issue or PR as belonging to the sig.
+func sigLabel(sigName string) string {
+	label := sigLabelPrefix + sigName
+	if n := 20; len(label) > n {
+		label = label[:n]
+	}
+
+	return label
+}
+
+// syncSigLabel adds the sig label to the issue or PR, and removes the other
+// sig labels left by the sig which the repo belonged to before if removeStale is set.
+func (bot *robot) syncSigLabel(p *eventArgs, label string, removeStale bool) error {
+	// the label of empty sig name would make all the real sig labels stale
+	if label == sigLabelPrefix {
+		return fmt.Errorf("the sig name is empty")
+	}
+
+	var current *sets.String
+	var err error
+
+	if removeStale {
+		if current, err = bot.getLabels(p); err != nil {
+			return err
+		}
+	}
+
+	if current == nil || !current.Has(label) {
+		if err = bot.addLabels(p, []string{label}); err != nil {
+			return err
+		}
+	}
+
+	if current == nil {
+		return nil
+	}
+
+	var stale []string
+	for _, l := range current.UnsortedList() {
+		if strings.HasPrefix(l, sigLabelPrefix) && l != label {
+			stale = append(stale, l)
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	p.log.Infof("remove stale sig labels: %v", stale)
+
+	return bot.removeLabels(p, stale)
+}
+
+func (bot *robot) getLabels(p *eventArgs) (*sets.String, error) {
+	if p.flag == Issue {
+		return p.cli.GetIssueLabels(&sdk.IssueParameter{
+			Org:    p.event.Org,
+			Repo:   p.event.Repo,
+			Number: p.event.IssueNumber,
+		})
+	}
+
+	return p.cli.GetPRLabels(&sdk.PRParameter{
+		Org:    p.event.Org,
+		Repo:   p.event.Repo,
+		Number: p.event.PRNumber,
+	})
+}
+
+func (bot *robot) addLabels(p *eventArgs, labels []string) error {
+	if p.flag == Issue {
+		return p.cli.AddIssueLabels(&sdk.IssueParameter{
+			Org:    p.event.Org,
+			Repo:   p.event.Repo,
+			Number: p.event.IssueNumber,
+			Labels: labels,
+		})
+	}
+
+	return p.cli.AddPRLabels(&sdk.PRParameter{
+		Org:    p.event.Org,
+		Repo:   p.event.Repo,
+		Number: p.event.PRNumber,
+		Labels: labels,
+	})
+}
+
+func (bot *robot) removeLabels(p *eventArgs, labels []string) error {
+	if p.flag == Issue {
+		return p.cli.DeleteIssueLabels(&sdk.IssueParameter{
+			Org:    p.event.Org,
+			Repo:   p.event.Repo,
+			Number: p.event.IssueNumber,
+			Labels: labels,
+		})
+	}
+
+	return p.cli.DeletePRLabels(&sdk.PRParameter{
+		Org:    p.event.Org,
+		Repo:   p.event.Repo,
+		Number: p.event.PRNumber,
+		Labels: labels,
+	})
+}
+
+// relabelOpenItems syncs the sig label of all the open issues and PRs of the repos
+// and removes the stale ones. The repos are in the form of org/repo.
+func (bot *robot) relabelOpenItems(cfg *configuration, repos []string, log *logrus.Entry) error {
+	mErr := utils.NewMultiErrors()
+
+	for _, orgRepo := range repos {
+		v := strings.Split(orgRepo, "/")
+		if len(v) != 2 || v[0] == "" || v[1] == "" {
+			mErr.Add(fmt.Sprintf("invalid repo: %s", orgRepo))
+			continue
+		}
+
+		mErr.AddError(bot.relabelRepo(cfg, v[0], v[1], log.WithField("repo", orgRepo)))
+	}
+
+	return mErr.Err()
+}
+
+func (bot *robot) relabelRepo(cfg *configuration, org, repo string, log *logrus.Entry) error {
+	bc, err := cfg.configFor(org, repo)
+	if err != nil {
+		return err
+	}
+
+	if bc == nil {
+		return fmt.Errorf("no config for this repo:%s/%s", org, repo)
+	}
+
+	sigName, err := bot.sigCli.GetSigNameByOrgRepo(org, repo)
+	if err != nil {
+		return err
+	}
+
+	if sigName == "" {
+		return fmt.Errorf("the repo:%s/%s belongs to no sig", org, repo)
+	}
+
+	cli := bot.clientFor(bc)
+
+	label := sigLabel(sigName)
+	if err = bot.createLabelIfNeed(cli, org, repo, label); err != nil {
+		return err
+	}
+
+	mErr := utils.NewMultiErrors()
+
+	issues, err := cli.ListOpenIssues(org, repo)
+	mErr.AddError(err)
+
+	for _, n := range issues {
+		p := &eventArgs{
+			cli:    cli,
+			sigCli: bot.sigCli,
+			flag:   Issue,
+			event:  &sdk.GenericEvent{Org: org, Repo: repo, IssueNumber: n},
+			cnf:    bc,
+			log:    log.WithField("issue", n),
+		}
+		mErr.AddError(bot.syncSigLabel(p, label, true))
+	}
+
+	prs, err := cli.ListOpenPRs(org, repo)
+	mErr.AddError(err)
+
+	for _, n := range prs {
+		p := &eventArgs{
+			cli:    cli,
+			sigCli: bot.sigCli,
+			flag:   PullRequest,
+			event:  &sdk.GenericEvent{Org: org, Repo: repo, PRNumber: n},
+			cnf:    bc,
+			log:    log.WithField("pr", n),
+		}
+		mErr.AddError(bot.syncSigLabel(p, label, true))
+	}
+
+	return mErr.Err()
+}